@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	code.rocketnine.space/tslocum/cbind v0.1.5
+	github.com/andybalholm/brotli v1.0.4
 	github.com/atotto/clipboard v0.1.4
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/dgraph-io/ristretto v0.1.0
@@ -20,6 +21,7 @@ require (
 	github.com/ulikunitz/xz v0.5.10
 	golang.org/x/sync v0.0.0-20220513210516-0976fa681c29
 	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467
+	golang.org/x/text v0.3.7
 	gopkg.in/ini.v1 v1.66.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -48,7 +48,7 @@ var rootCmd = &cobra.Command{
 	Use:   "ov",
 	Short: "ov is a feature rich pager",
 	Long: `ov is a feature rich pager(such as more/less).
-It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
+It supports various compressed files(gzip, bzip2, zstd, lz4, xz, brotli, snappy, zlib, lzma and lzip).
 `,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -76,6 +76,15 @@ It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
 		// Set a global variable to convert to a style before opening the file.
 		oviewer.OverStrikeStyle = oviewer.ToTcellStyle(config.StyleOverStrike)
 		oviewer.OverLineStyle = oviewer.ToTcellStyle(config.StyleOverLine)
+		// Set the encoding before opening the file.
+		oviewer.DefaultEncoding = config.General.Encoding
+		// Set the history file before opening the file.
+		if !config.DisableHistory {
+			oviewer.HistoryFile = oviewer.DefaultHistoryFile()
+		}
+		if config.HistorySize > 0 {
+			oviewer.HistorySize = config.HistorySize
+		}
 
 		SetRedirect()
 
@@ -240,6 +249,27 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("wrap", "w", true, "wrap mode")
 	_ = viper.BindPFlag("general.WrapMode", rootCmd.PersistentFlags().Lookup("wrap"))
 
+	rootCmd.PersistentFlags().BoolP("hex", "", false, "hex dump mode")
+	_ = viper.BindPFlag("general.HexMode", rootCmd.PersistentFlags().Lookup("hex"))
+
+	rootCmd.PersistentFlags().BoolP("detect-delimiter", "", true, "detect the delimiter and enable column mode")
+	_ = viper.BindPFlag("general.DetectDelimiter", rootCmd.PersistentFlags().Lookup("detect-delimiter"))
+
+	rootCmd.PersistentFlags().BoolP("align", "", false, "align columns")
+	_ = viper.BindPFlag("general.AlignMode", rootCmd.PersistentFlags().Lookup("align"))
+
+	rootCmd.PersistentFlags().BoolP("column-width", "", false, "column mode by the widths of the columns")
+	_ = viper.BindPFlag("general.ColumnWidth", rootCmd.PersistentFlags().Lookup("column-width"))
+
+	rootCmd.PersistentFlags().StringSliceP("column-layout", "", nil, "columns to display in order (header names or numbers)")
+	_ = viper.BindPFlag("general.ColumnLayout", rootCmd.PersistentFlags().Lookup("column-layout"))
+
+	rootCmd.PersistentFlags().IntP("pin-columns", "", 0, "number of columns to pin on the left")
+	_ = viper.BindPFlag("general.PinColumns", rootCmd.PersistentFlags().Lookup("pin-columns"))
+
+	rootCmd.PersistentFlags().BoolP("column-csv", "", false, "split columns as CSV with quoted fields")
+	_ = viper.BindPFlag("general.ColumnCSV", rootCmd.PersistentFlags().Lookup("column-csv"))
+
 	rootCmd.PersistentFlags().StringP("column-delimiter", "d", ",", "column delimiter")
 	_ = viper.BindPFlag("general.ColumnDelimiter", rootCmd.PersistentFlags().Lookup("column-delimiter"))
 	_ = rootCmd.RegisterFlagCompletionFunc("column-delimiter", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -261,6 +291,15 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("follow-section", "", false, "follow section")
 	_ = viper.BindPFlag("general.FollowSection", rootCmd.PersistentFlags().Lookup("follow-section"))
 
+	rootCmd.PersistentFlags().BoolP("follow-name", "", false, "follow name mode")
+	_ = viper.BindPFlag("general.FollowName", rootCmd.PersistentFlags().Lookup("follow-name"))
+
+	rootCmd.PersistentFlags().StringP("encoding", "", "", "character encoding of input (default UTF-8)")
+	_ = viper.BindPFlag("general.Encoding", rootCmd.PersistentFlags().Lookup("encoding"))
+	_ = rootCmd.RegisterFlagCompletionFunc("encoding", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"UTF-8", "UTF-16LE", "UTF-16BE", "Shift_JIS", "EUC-JP", "ISO-2022-JP", "EUC-KR", "GBK", "Big5", "windows-1252"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	rootCmd.PersistentFlags().IntP("watch", "T", 0, "watch mode interval")
 	_ = viper.BindPFlag("general.WatchInterval", rootCmd.PersistentFlags().Lookup("watch"))
 
@@ -286,6 +325,21 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("regexp-search", "", false, "regular expression search")
 	_ = viper.BindPFlag("RegexpSearch", rootCmd.PersistentFlags().Lookup("regexp-search"))
 
+	rootCmd.PersistentFlags().BoolP("smart-case", "", false, "case-insensitive unless the search word contains uppercase")
+	_ = viper.BindPFlag("SmartCase", rootCmd.PersistentFlags().Lookup("smart-case"))
+
+	rootCmd.PersistentFlags().BoolP("whole-word", "", false, "search only whole words")
+	_ = viper.BindPFlag("WholeWord", rootCmd.PersistentFlags().Lookup("whole-word"))
+
+	rootCmd.PersistentFlags().BoolP("wildcard-search", "", false, "literal search with wildcards (* and ?)")
+	_ = viper.BindPFlag("WildcardSearch", rootCmd.PersistentFlags().Lookup("wildcard-search"))
+
+	rootCmd.PersistentFlags().BoolP("multiline-search", "", false, "search across lines")
+	_ = viper.BindPFlag("MultilineSearch", rootCmd.PersistentFlags().Lookup("multiline-search"))
+
+	rootCmd.PersistentFlags().BoolP("fuzzy-search", "", false, "fuzzy search in order of the score")
+	_ = viper.BindPFlag("FuzzySearch", rootCmd.PersistentFlags().Lookup("fuzzy-search"))
+
 	rootCmd.PersistentFlags().BoolP("incsearch", "", true, "incremental search")
 	_ = viper.BindPFlag("Incsearch", rootCmd.PersistentFlags().Lookup("incsearch"))
 
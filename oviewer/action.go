@@ -1,6 +1,7 @@
 package oviewer
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +23,16 @@ func (root *Root) toggleWrapMode() {
 	root.setMessagef("Set WrapMode %t", root.Doc.WrapMode)
 }
 
+// toggleHexMode toggles HexMode each time it is called.
+func (root *Root) toggleHexMode() {
+	root.Doc.HexMode = !root.Doc.HexMode
+	root.Doc.setHexMode()
+	root.Doc.topLN = 0
+	root.Doc.topLX = 0
+	root.Doc.x = 0
+	root.setMessagef("Set HexMode %t", root.Doc.HexMode)
+}
+
 //  toggleColumnMode toggles ColumnMode each time it is called.
 func (root *Root) toggleColumnMode() {
 	root.Doc.ColumnMode = !root.Doc.ColumnMode
@@ -55,6 +67,80 @@ func (root *Root) toggleFollowSection() {
 	root.Doc.FollowSection = !root.Doc.FollowSection
 }
 
+// openMember opens the member on the top line of the archive listing as a new document.
+func (root *Root) openMember() {
+	m := root.Doc
+	if m.ArchiveFormat() == NOARCHIVE {
+		root.setMessage(ErrNotArchive.Error())
+		return
+	}
+
+	doc, err := m.MemberDocument(m.topLN + m.firstLine())
+	if err != nil {
+		root.setMessagef("cannot open member: %s", err)
+		return
+	}
+	root.AddDocument(doc)
+}
+
+// filter adds a document that contains only the lines matching the input.
+// The input starting with "!" contains the lines that do not match.
+func (root *Root) filter(ctx context.Context, input string) {
+	word := input
+	invert := strings.HasPrefix(word, "!")
+	if invert {
+		word = word[1:]
+	}
+	searcher := root.setSearcher(word, root.CaseSensitive)
+	if searcher == nil {
+		return
+	}
+
+	m := root.Doc
+	doc, err := m.FilterDocument(ctx, searcher, invert)
+	if err != nil {
+		root.setMessagef("cannot filter: %s", err)
+		return
+	}
+	doc.FileName = fmt.Sprintf("%s:filter:%s", m.FileName, input)
+	root.addDocument(doc)
+}
+
+// occur adds a document that lists the lines matching the input with the line numbers.
+func (root *Root) occur(ctx context.Context, input string) {
+	searcher := root.setSearcher(input, root.CaseSensitive)
+	if searcher == nil {
+		return
+	}
+
+	m := root.Doc
+	doc, err := m.OccurDocument(ctx, searcher)
+	if err != nil {
+		root.setMessagef("cannot occur: %s", err)
+		return
+	}
+	doc.FileName = fmt.Sprintf("%s:occur:%s", m.FileName, input)
+	root.addDocument(doc)
+}
+
+// originLine moves to the original line of the top line of the filtered document.
+func (root *Root) originLine() {
+	m := root.Doc
+	lN, ok := m.originLine(m.topLN + m.firstLine())
+	if !ok {
+		root.setMessage(ErrNotFiltered.Error())
+		return
+	}
+
+	docNum := root.docNum(m.filter.parent)
+	if docNum < 0 {
+		root.setMessagef("%s %s", m.filter.parent.FileName, ErrAlreadyClose)
+		return
+	}
+	root.switchDocument(docNum)
+	root.goLineNumber(lN)
+}
+
 // closeFile close the file.
 func (root *Root) closeFile() {
 	if root.screenMode != Docs {
@@ -83,6 +169,11 @@ func (root *Root) reload(m *Document) {
 		log.Printf("cannot reload: %s", err)
 		return
 	}
+	// The matches are counted again from the beginning.
+	m.stopCountMatch()
+	if m == root.Doc {
+		root.countMatch()
+	}
 	root.releaseEventBuffer()
 	// Reserve time to read.
 	time.Sleep(100 * time.Millisecond)
@@ -130,7 +221,14 @@ func (root *Root) watchStart() {
 }
 
 // goLine will move to the specified line.
+// In hex mode, it moves to the row containing the specified byte offset.
 func (root *Root) goLine(input string) {
+	if root.Doc.hexMode() {
+		root.goOffset(input)
+		return
+	}
+	// The line number is that of the document.
+	root.Doc.dropTail()
 	if !strings.Contains(input, ".") {
 		// Line number only.
 		lN, err := strconv.Atoi(input)
@@ -159,6 +257,18 @@ func (root *Root) goLine(input string) {
 	root.setMessagef("Moved to line %d.%d", lN+1, nTh)
 }
 
+// goOffset moves to the row containing the byte offset.
+// The offset is decimal or hexadecimal with 0x.
+func (root *Root) goOffset(input string) {
+	offset, err := strconv.ParseInt(input, 0, 64)
+	if err != nil || offset < 0 {
+		root.setMessage(ErrInvalidNumber.Error())
+		return
+	}
+	root.moveLine(int(offset/hexRowBytes) - root.Doc.firstLine())
+	root.setMessagef("Moved to offset 0x%x", offset)
+}
+
 // goLineNumber moves to the specified line number.
 func (root *Root) goLineNumber(ln int) {
 	ln = root.moveLine(ln - root.Doc.firstLine())
@@ -195,7 +305,7 @@ func (root *Root) markPrev() {
 
 // addMark marks the current line number.
 func (root *Root) addMark() {
-	c := min(root.Doc.topLN+root.Doc.firstLine(), root.Doc.BufEndNum())
+	c := min(root.Doc.topLN+root.Doc.firstLine(), root.Doc.viewEndNum())
 	root.Doc.marked = removeInt(root.Doc.marked, c)
 	root.Doc.marked = append(root.Doc.marked, c)
 	root.setMessagef("Marked to line %d", c-root.Doc.firstLine()+1)
@@ -314,9 +424,15 @@ func (root *Root) setViewMode(input string) {
 		c = root.General
 	}
 
+	encoding := root.Doc.Encoding
 	root.Doc.general = overwriteGeneral(root.Doc.general, c)
 	root.Doc.setSectionDelimiter(root.Doc.SectionDelimiter)
+	root.Doc.setHexMode()
 	root.Doc.ClearCache()
+	// Read again to convert with the encoding of the mode.
+	if root.Doc.Encoding != encoding {
+		root.reload(root.Doc)
+	}
 	root.ViewSync()
 	root.setMessagef("Set mode %s", input)
 }
@@ -324,6 +440,9 @@ func (root *Root) setViewMode(input string) {
 // setDelimiter sets the delimiter string.
 func (root *Root) setDelimiter(input string) {
 	root.Doc.ColumnDelimiter = input
+	// The detected delimiter is overridden.
+	root.Doc.delimiterGuess = ""
+	root.Doc.ColumnWidth = false
 	root.setMessagef("Set delimiter %s", input)
 }
 
@@ -439,7 +558,7 @@ func (root *Root) TailSync() {
 func (root *Root) tailSection() {
 	moved := root.Doc.topLN - root.Doc.lastSectionPosNum
 	root.lastSection()
-	if moved > 0 && (root.Doc.topLN+moved) < root.Doc.BufEndNum() {
+	if moved > 0 && (root.Doc.topLN+moved) < root.Doc.viewEndNum() {
 		root.moveLine(root.Doc.topLN + moved)
 	}
 	root.Doc.lastSectionPosNum = root.Doc.topLN
@@ -449,13 +568,23 @@ func (root *Root) tailSection() {
 func (root *Root) prepareStartX() {
 	root.startX = 0
 	if root.Doc.LineNumMode {
-		root.startX = len(fmt.Sprintf("%d", root.Doc.BufEndNum())) + 1
+		endNum := root.Doc.viewEndNum()
+		if root.Doc.filter != nil {
+			endNum = root.Doc.filter.parent.BufEndNum()
+		}
+		root.startX = len(fmt.Sprintf("%d", endNum)) + 1
 	}
 }
 
 // updateEndNum updates the last line number.
 func (root *Root) updateEndNum() {
-	root.debugMessage(fmt.Sprintf("Update EndNum:%d", root.Doc.BufEndNum()))
+	root.Doc.closeTail()
+	if atomic.SwapInt32(&root.Doc.truncated, 0) == 1 {
+		root.Doc.topLN = 0
+		root.Doc.marked = nil
+		root.setMessagef("%s truncated", root.Doc.FileName)
+	}
+	root.debugMessage(fmt.Sprintf("Update EndNum:%d", root.Doc.viewEndNum()))
 	root.prepareStartX()
 	root.drawStatus()
 	root.Screen.Sync()
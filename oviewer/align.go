@@ -0,0 +1,219 @@
+package oviewer
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// alignMaxWidth is the maximum width of a column in align mode.
+// The longer field is truncated with an ellipsis.
+var alignMaxWidth = 40
+
+// alignSampleLines is the number of lines from the top of the body sampled for the widths of the columns.
+const alignSampleLines = 1000
+
+// alignSeparator separates the columns in align mode.
+const alignSeparator = " │ "
+
+// alignColumns represents the widths of the columns in align mode.
+// The widths only increase so that the columns do not move while scrolling.
+type alignColumns struct {
+	widths []int
+	// version is incremented when the widths change.
+	version int
+	// scanned is the number of lines sampled from the top of the document.
+	scanned int
+	// sampled is the position of the screen sampled last.
+	sampled [3]int
+	// delimiter and csv are the settings that the widths are computed with.
+	delimiter string
+	csv       bool
+}
+
+// alignEnabled returns true if the columns are aligned.
+func (m *Document) alignEnabled() bool {
+	if m.hexMode() {
+		return false
+	}
+	if m.layoutEnabled() {
+		return true
+	}
+	return m.AlignMode && m.ColumnDelimiter != "" && !m.ColumnWidth
+}
+
+// lineFields returns the fields of the line.
+// The empty last field is not contained.
+func (m *Document) lineFields(s string) []string {
+	var fields []string
+	for n := 0; ; n++ {
+		start, end := m.fieldRange(s, n)
+		if start < 0 || end < 0 || start >= len(s) {
+			break
+		}
+		fields = append(fields, s[start:end])
+		if end >= len(s) {
+			break
+		}
+	}
+	return fields
+}
+
+// alignFields returns the fields of the line to align in the order of the layout.
+// Returns nil if the line is not a row of the table.
+func (m *Document) alignFields(s string) []string {
+	fields := m.lineFields(stripEscapeSequence(s))
+	if len(fields) < 2 {
+		return nil
+	}
+	if m.ColumnWidth {
+		for i, f := range fields {
+			fields[i] = strings.TrimRight(f, " ")
+		}
+	}
+	return m.viewFields(fields)
+}
+
+// fieldWidth returns the display width of the field.
+func fieldWidth(field string) int {
+	return runewidth.StringWidth(strings.ReplaceAll(field, "\t", " "))
+}
+
+// resetAlign discards the widths of the columns.
+func (m *Document) resetAlign() {
+	m.align = alignColumns{
+		version:   m.align.version + 1,
+		delimiter: m.ColumnDelimiter,
+		csv:       m.ColumnCSV,
+	}
+}
+
+// updateAlign updates the widths of the columns with the header,
+// the lines from the top of the body and the lines on the screen from topLN.
+// The lines added in follow mode are sampled when they are displayed.
+func (m *Document) updateAlign(topLN int, height int) {
+	if !m.alignEnabled() {
+		return
+	}
+	endNum := m.viewEndNum()
+	// The settings are changed or the document is reloaded.
+	if m.align.delimiter != m.ColumnDelimiter || m.align.csv != m.ColumnCSV || m.align.scanned > endNum {
+		m.resetAlign()
+	}
+	key := [3]int{topLN, height, endNum}
+	if m.align.sampled == key {
+		return
+	}
+	m.align.sampled = key
+
+	changed := false
+	sample := func(lN int) {
+		fields := m.alignFields(m.viewLine(lN))
+		if fields == nil {
+			return
+		}
+		for i, f := range fields {
+			w := min(fieldWidth(f), alignMaxWidth)
+			if i >= len(m.align.widths) {
+				m.align.widths = append(m.align.widths, w)
+				changed = true
+				continue
+			}
+			if w > m.align.widths[i] {
+				m.align.widths[i] = w
+				changed = true
+			}
+		}
+	}
+
+	limit := min(m.firstLine()+alignSampleLines, endNum)
+	for ; m.align.scanned < limit; m.align.scanned++ {
+		sample(m.align.scanned)
+	}
+	start := m.firstLine() + topLN
+	for lN := start; lN < start+height && lN < endNum; lN++ {
+		sample(lN)
+	}
+	if changed {
+		m.align.version++
+	}
+}
+
+// alignLine returns the line with the fields padded to the widths of the columns.
+// Returns false if the line is not a row of the table.
+func (m *Document) alignLine(s string) (string, bool) {
+	fields := m.alignFields(s)
+	if fields == nil {
+		return s, false
+	}
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString(alignSeparator)
+		}
+		f = strings.ReplaceAll(f, "\t", " ")
+		w := alignMaxWidth
+		if i < len(m.align.widths) {
+			w = m.align.widths[i]
+		}
+		if fieldWidth(f) > w {
+			f = runewidth.Truncate(f, w, "…")
+		}
+		b.WriteString(f)
+		b.WriteString(strings.Repeat(" ", w-fieldWidth(f)))
+	}
+	return b.String(), true
+}
+
+// alignRange returns the range of the number-th column of the aligned line.
+func (m *Document) alignRange(s string, number int) (int, int) {
+	widths := m.align.widths
+	if number < 0 || number >= len(widths) {
+		return -1, -1
+	}
+	sepWidth := runewidth.StringWidth(alignSeparator)
+	startCell := 0
+	for _, w := range widths[:number] {
+		startCell += w + sepWidth
+	}
+	endCell := startCell + widths[number]
+
+	start, end := -1, -1
+	cell := 0
+	for i, r := range s {
+		if cell == startCell && start < 0 {
+			start = i
+		}
+		if cell == endCell {
+			end = i
+			break
+		}
+		cell += runewidth.RuneWidth(r)
+	}
+	if start < 0 && cell == startCell {
+		start = len(s)
+	}
+	if end < 0 && cell == endCell {
+		end = len(s)
+	}
+	if start < 0 || end < 0 {
+		return -1, -1
+	}
+	if number > 0 && !strings.HasSuffix(s[:start], alignSeparator) {
+		return -1, -1
+	}
+	return start, end
+}
+
+// toggleAlignMode toggles AlignMode each time it is called.
+func (root *Root) toggleAlignMode() {
+	m := root.Doc
+	m.AlignMode = !m.AlignMode
+	m.resetAlign()
+	m.x = 0
+	if m.AlignMode && m.ColumnDelimiter == "" {
+		root.setMessage("Set the delimiter for AlignMode")
+		return
+	}
+	root.setMessagef("Set AlignMode %t", m.AlignMode)
+}
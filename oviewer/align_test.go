@@ -0,0 +1,112 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func alignTestDocument(t *testing.T, str string) *Document {
+	t.Helper()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(str)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	m.ColumnDelimiter = ","
+	m.AlignMode = true
+	return m
+}
+
+func TestDocument_alignLine(t *testing.T) {
+	m := alignTestDocument(t, "id,name,age\n1,Alice,30\n22,Bob,4\n")
+	m.updateAlign(0, 10)
+	tests := []struct {
+		name   string
+		s      string
+		want   string
+		wantOK bool
+	}{
+		{name: "testHeader", s: "id,name,age", want: "id │ name  │ age", wantOK: true},
+		{name: "testPadding", s: "22,Bob,4", want: "22 │ Bob   │ 4  ", wantOK: true},
+		{name: "testNotRow", s: "(2 rows)", want: "(2 rows)", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.alignLine(tt.s)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Document.alignLine() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDocument_alignLineTruncate(t *testing.T) {
+	m := alignTestDocument(t, "a,"+strings.Repeat("x", 100)+"\n")
+	m.updateAlign(0, 10)
+	got, _ := m.alignLine("a," + strings.Repeat("x", 100))
+	want := "a │ " + strings.Repeat("x", alignMaxWidth-1) + "…"
+	if got != want {
+		t.Errorf("Document.alignLine() = %q, want %q", got, want)
+	}
+}
+
+func TestDocument_alignRange(t *testing.T) {
+	m := alignTestDocument(t, "id,name,age\n1,Alice,30\n")
+	m.updateAlign(0, 10)
+	s, _ := m.alignLine("1,Alice,30")
+	tests := []struct {
+		name   string
+		number int
+		want   string
+	}{
+		{name: "testFirst", number: 0, want: "1 "},
+		{name: "testSecond", number: 1, want: "Alice"},
+		{name: "testLast", number: 2, want: "30 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := m.alignRange(s, tt.number)
+			if start < 0 || end < 0 {
+				t.Fatalf("Document.alignRange() = %v, %v", start, end)
+			}
+			if got := s[start:end]; got != tt.want {
+				t.Errorf("Document.alignRange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if start, end := m.alignRange(s, 3); start != -1 || end != -1 {
+		t.Errorf("Document.alignRange() = %v, %v, want -1, -1", start, end)
+	}
+}
+
+func TestDocument_updateAlign(t *testing.T) {
+	m := alignTestDocument(t, "id,name\n1,Alice\n")
+	m.updateAlign(0, 10)
+	if got := m.align.widths; len(got) != 2 || got[0] != 2 || got[1] != 5 {
+		t.Fatalf("Document.updateAlign() widths = %v, want [2 5]", got)
+	}
+	version := m.align.version
+
+	// The lines added in follow mode widen the columns.
+	m.mu.Lock()
+	m.lines = append(m.lines, "12345,Bob")
+	m.endNum++
+	m.mu.Unlock()
+	m.updateAlign(0, 10)
+	if got := m.align.widths; got[0] != 5 || got[1] != 5 {
+		t.Errorf("Document.updateAlign() widths = %v, want [5 5]", got)
+	}
+	if m.align.version == version {
+		t.Errorf("Document.updateAlign() version is not changed")
+	}
+
+	// The widths are recomputed with the new delimiter.
+	m.ColumnDelimiter = "|"
+	m.updateAlign(0, 10)
+	if got := m.align.widths; len(got) != 0 {
+		t.Errorf("Document.updateAlign() widths = %v, want []", got)
+	}
+}
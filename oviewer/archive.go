@@ -0,0 +1,247 @@
+package oviewer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// Archive represents the type of archive.
+type Archive int
+
+const (
+	// NOARCHIVE is not an archive.
+	NOARCHIVE Archive = iota
+	// TAR is tar archive format.
+	TAR
+	// ZIP is zip archive format.
+	ZIP
+)
+
+// archiveHeaderLen is the length of the header needed to detect the archive.
+const archiveHeaderLen = 262
+
+// archiveType returns the type of archive detected from the header.
+func archiveType(header []byte) Archive {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return ZIP
+	case len(header) >= archiveHeaderLen && bytes.Equal(header[257:262], []byte("ustar")):
+		return TAR
+	}
+	return NOARCHIVE
+}
+
+func (a Archive) String() string {
+	switch a {
+	case TAR:
+		return "TAR"
+	case ZIP:
+		return "ZIP"
+	}
+	return "NOARCHIVE"
+}
+
+// archive represents the members of the archive.
+// The document of the archive is a listing of members,
+// and the line number is the member number.
+type archive struct {
+	// format is the type of archive.
+	format Archive
+	// members is the list of members.
+	members []archiveMember
+}
+
+// archiveMember represents a member of the archive.
+type archiveMember struct {
+	// name is the name of the member.
+	name string
+	// isDir is true if the member is a directory.
+	isDir bool
+	// zipFile is the member of the zip archive.
+	zipFile *zip.File
+}
+
+// memberReader is a reader of a member that closes the archive.
+type memberReader struct {
+	io.Reader
+	io.Closer
+}
+
+// memberLine returns a line of the listing.
+func memberLine(fi fs.FileInfo, name string) string {
+	return fmt.Sprintf("%s %10d %s %s", fi.Mode(), fi.Size(), fi.ModTime().Format("2006-01-02 15:04"), name)
+}
+
+// readArchive reads the listing of the archive instead of the contents.
+// The tar archive is read as a stream, and the zip archive is read from the file.
+// m.mu must be locked.
+func (m *Document) readArchive(format Archive, r io.Reader) error {
+	m.archive = &archive{format: format}
+	pr, pw := io.Pipe()
+	switch format {
+	case TAR:
+		go m.listTar(tar.NewReader(r), pw)
+	case ZIP:
+		zr, err := m.zipReader(r)
+		if err != nil {
+			return err
+		}
+		go m.listZip(zr, pw)
+	}
+	return m.ReadAll(pr)
+}
+
+// zipReader returns a zip.Reader of the file.
+// The compressed zip archive is decompressed into memory.
+func (m *Document) zipReader(r io.Reader) (*zip.Reader, error) {
+	if m.CFormat != UNCOMPRESSED {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	}
+	fi, err := m.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(m.file, fi.Size())
+}
+
+// listTar writes the listing of the tar archive.
+func (m *Document) listTar(tr *tar.Reader, w *io.PipeWriter) {
+	defer w.Close()
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("listTar: %s", err)
+			}
+			return
+		}
+		m.addMember(archiveMember{
+			name:  hdr.Name,
+			isDir: hdr.FileInfo().IsDir(),
+		})
+		if _, err := fmt.Fprintln(w, memberLine(hdr.FileInfo(), hdr.Name)); err != nil {
+			return
+		}
+	}
+}
+
+// listZip writes the listing of the zip archive.
+func (m *Document) listZip(zr *zip.Reader, w *io.PipeWriter) {
+	defer w.Close()
+	for _, f := range zr.File {
+		m.addMember(archiveMember{
+			name:    f.Name,
+			isDir:   f.FileInfo().IsDir(),
+			zipFile: f,
+		})
+		if _, err := fmt.Fprintln(w, memberLine(f.FileInfo(), f.Name)); err != nil {
+			return
+		}
+	}
+}
+
+// addMember adds a member to the archive.
+func (m *Document) addMember(member archiveMember) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archive.members = append(m.archive.members, member)
+}
+
+// member returns member n of the archive.
+func (m *Document) member(n int) (archive, archiveMember, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.archive == nil {
+		return archive{}, archiveMember{}, ErrNotArchive
+	}
+	if n < 0 || n >= len(m.archive.members) {
+		return archive{}, archiveMember{}, ErrOutOfRange
+	}
+	return *m.archive, m.archive.members[n], nil
+}
+
+// openMember returns a reader of member n of the archive.
+// The tar archive is read again from the beginning to the member.
+func (m *Document) openMember(n int) (io.ReadCloser, error) {
+	a, member, err := m.member(n)
+	if err != nil {
+		return nil, err
+	}
+	if member.isDir {
+		return nil, fmt.Errorf("%s %w", member.name, ErrIsDirectory)
+	}
+
+	if a.format == ZIP {
+		return member.zipFile.Open()
+	}
+
+	f, err := os.Open(m.FileName)
+	if err != nil {
+		return nil, err
+	}
+	_, r := uncompressedReader(f, m.FileName)
+	tr := tar.NewReader(r)
+	for i := 0; i <= n; i++ {
+		if _, err := tr.Next(); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", member.name, err)
+		}
+	}
+	return memberReader{Reader: tr, Closer: f}, nil
+}
+
+// MemberDocument returns a Document that reads member n of the archive.
+func (m *Document) MemberDocument(n int) (*Document, error) {
+	rc, err := m.openMember(n)
+	if err != nil {
+		return nil, err
+	}
+	_, member, _ := m.member(n)
+
+	doc, err := NewDocument()
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	doc.FileName = m.FileName + ":" + member.name
+	doc.seekable = false
+	doc.preventReload = true
+
+	cFormat, r := uncompressedReader(rc, member.name)
+	doc.CFormat = cFormat
+	go func() {
+		<-doc.eofCh
+		rc.Close()
+	}()
+	if err := doc.ReadAll(r); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ArchiveFormat returns the type of archive if the document is a listing of the archive.
+func (m *Document) ArchiveFormat() Archive {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.archive == nil {
+		return NOARCHIVE
+	}
+	return m.archive.format
+}
+
+// peekArchive returns the type of archive from the beginning of the reader.
+func peekArchive(r *bufio.Reader) Archive {
+	header, _ := r.Peek(archiveHeaderLen)
+	return archiveType(header)
+}
@@ -0,0 +1,178 @@
+package oviewer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_archiveType(t *testing.T) {
+	tarHeader := make([]byte, 512)
+	copy(tarHeader[257:], "ustar")
+	tests := []struct {
+		name   string
+		header []byte
+		want   Archive
+	}{
+		{
+			name:   "testTar",
+			header: tarHeader,
+			want:   TAR,
+		},
+		{
+			name:   "testZip",
+			header: []byte("PK\x03\x04\x14\x00"),
+			want:   ZIP,
+		},
+		{
+			name:   "testEmptyZip",
+			header: []byte("PK\x05\x06\x00\x00"),
+			want:   ZIP,
+		},
+		{
+			name:   "testText",
+			header: []byte("test\n"),
+			want:   NOARCHIVE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := archiveType(tt.header); got != tt.want {
+				t.Errorf("archiveType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testMember struct {
+	name string
+	body string
+}
+
+var testMembers = []testMember{
+	{name: "dir/", body: ""},
+	{name: "dir/a.txt", body: "a1\na2\n"},
+	{name: "b.txt", body: "b1\n"},
+}
+
+func testTarGz(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, member := range testMembers {
+		hdr := &tar.Header{Name: member.name, Mode: 0o644, Size: int64(len(member.body)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(member.name, "/") {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(member.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, member := range testMembers {
+		w, err := zw.Create(member.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(member.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocument_MemberDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		want     Archive
+	}{
+		{
+			name:     "testTarGz",
+			fileName: "test.tar.gz",
+			data:     testTarGz(t),
+			want:     TAR,
+		},
+		{
+			name:     "testZip",
+			fileName: "test.zip",
+			data:     testZip(t),
+			want:     ZIP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(fileName, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m, err := OpenDocument(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := m.ArchiveFormat(); got != tt.want {
+				t.Fatalf("Document.ArchiveFormat() = %v, want %v", got, tt.want)
+			}
+			if got := m.BufEndNum(); got != len(testMembers) {
+				t.Fatalf("Document.BufEndNum() = %v, want %v", got, len(testMembers))
+			}
+			for n, member := range testMembers {
+				if got := m.GetLine(n); !strings.HasSuffix(got, " "+member.name) {
+					t.Errorf("Document.GetLine(%d) = %v, want member %v", n, got, member.name)
+				}
+			}
+
+			if _, err := m.MemberDocument(0); !errors.Is(err, ErrIsDirectory) {
+				t.Errorf("Document.MemberDocument(0) error = %v, want %v", err, ErrIsDirectory)
+			}
+			for n := 1; n < len(testMembers); n++ {
+				doc, err := m.MemberDocument(n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for !doc.BufEOF() {
+				}
+				want := strings.Split(strings.TrimSuffix(testMembers[n].body, "\n"), "\n")
+				if got := doc.BufEndNum(); got != len(want) {
+					t.Fatalf("member Document.BufEndNum() = %v, want %v", got, len(want))
+				}
+				for i, w := range want {
+					if got := doc.GetLine(i); got != w {
+						t.Errorf("member Document.GetLine(%d) = %v, want %v", i, got, w)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package oviewer
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -53,3 +57,40 @@ func Draw_Helper(b *testing.B, fileName string) {
 	}
 	root.Close()
 }
+
+func BenchmarkSearchLine_Sequential(b *testing.B) {
+	SearchLine_Helper(b, 1)
+}
+
+func BenchmarkSearchLine_Parallel(b *testing.B) {
+	SearchLine_Helper(b, runtime.GOMAXPROCS(0))
+}
+
+// SearchLine_Helper searches the last line of the ANSI escaped lines with the workers.
+func SearchLine_Helper(b *testing.B, workers int) {
+	var s strings.Builder
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&s, "\x1b[32m%d\x1b[0m \x1b[1mINFO\x1b[0m request completed in \x1b[33m%dms\x1b[0m\n", i, i%1000)
+	}
+	s.WriteString("\x1b[31mERROR\x1b[0m request failed\n")
+	m, err := NewDocument()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(s.String())); err != nil {
+		b.Fatal(err)
+	}
+	<-m.eofCh
+
+	defer func(n int) {
+		searchWorkers = n
+	}(searchWorkers)
+	searchWorkers = workers
+	searcher := NewSearcher("ERROR", nil, false, false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.SearchLine(context.Background(), searcher, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
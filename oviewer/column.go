@@ -0,0 +1,79 @@
+package oviewer
+
+import (
+	"strings"
+)
+
+// csvRangePosition returns the range of the number-th field of the CSV (RFC 4180) line.
+// A quoted field can contain the delimiter and the escaped quotes ("").
+// The range of the quoted field contains the quotes.
+// Returns -1, -1 if there is no field.
+func csvRangePosition(s, delimiter string, number int) (int, int) {
+	if delimiter == "" {
+		return -1, -1
+	}
+	start := 0
+	for n := 0; ; n++ {
+		end := csvFieldEnd(s, delimiter, start)
+		if n == number {
+			return start, end
+		}
+		if end >= len(s) {
+			return -1, -1
+		}
+		start = end + len(delimiter)
+	}
+}
+
+// csvFieldEnd returns the end of the field starting at start.
+func csvFieldEnd(s, delimiter string, start int) int {
+	i := start
+	if i < len(s) && s[i] == '"' {
+		i++
+		for i < len(s) {
+			if s[i] == '"' {
+				// Escaped quote.
+				if i+1 < len(s) && s[i+1] == '"' {
+					i += 2
+					continue
+				}
+				i++
+				break
+			}
+			i++
+		}
+	}
+	if d := strings.Index(s[i:], delimiter); d >= 0 {
+		return i + d
+	}
+	return len(s)
+}
+
+// columnRange returns the range of the number-th column of the line of the contents.
+// In align mode, it is the range of the aligned column.
+func (m *Document) columnRange(s string, number int) (int, int) {
+	if m.alignEnabled() {
+		if start, end := m.alignRange(s, number); start >= 0 {
+			return start, end
+		}
+	}
+	return m.fieldRange(s, number)
+}
+
+// fieldRange returns the range of the number-th column of the line.
+// The columns are split by the widths in ColumnWidth mode.
+func (m *Document) fieldRange(s string, number int) (int, int) {
+	if m.ColumnWidth {
+		return widthRangePosition(s, m.columnBounds, number)
+	}
+	return m.delimiterRange(s, number)
+}
+
+// delimiterRange returns the range of the number-th column of the line split by the delimiter.
+// The columns are split as CSV if ColumnCSV is true.
+func (m *Document) delimiterRange(s string, number int) (int, int) {
+	if m.ColumnCSV {
+		return csvRangePosition(s, m.ColumnDelimiter, number)
+	}
+	return rangePosition(s, m.ColumnDelimiter, number)
+}
@@ -0,0 +1,51 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func Test_csvRangePosition(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         string
+		delimiter string
+		number    int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "testFirst", s: "a,b,c", delimiter: ",", number: 0, wantStart: 0, wantEnd: 1},
+		{name: "testLast", s: "a,b,c", delimiter: ",", number: 2, wantStart: 4, wantEnd: 5},
+		{name: "testNoField", s: "a,b,c", delimiter: ",", number: 3, wantStart: -1, wantEnd: -1},
+		{name: "testQuoted", s: `1,"Smith, John",30`, delimiter: ",", number: 1, wantStart: 2, wantEnd: 15},
+		{name: "testAfterQuoted", s: `1,"Smith, John",30`, delimiter: ",", number: 2, wantStart: 16, wantEnd: 18},
+		{name: "testEscapedQuote", s: `"a ""b"", c",d`, delimiter: ",", number: 1, wantStart: 13, wantEnd: 14},
+		{name: "testEmptyLast", s: "a,", delimiter: ",", number: 1, wantStart: 2, wantEnd: 2},
+		{name: "testUnterminated", s: `a,"b,c`, delimiter: ",", number: 1, wantStart: 2, wantEnd: 6},
+		{name: "testMultiByteDelimiter", s: `"x│y"│z`, delimiter: "│", number: 1, wantStart: 10, wantEnd: 11},
+		{name: "testNoDelimiter", s: "a,b", delimiter: "", number: 0, wantStart: -1, wantEnd: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStart, gotEnd := csvRangePosition(tt.s, tt.delimiter, tt.number)
+			if gotStart != tt.wantStart || gotEnd != tt.wantEnd {
+				t.Errorf("csvRangePosition() = %v, %v, want %v, %v", gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDocument_columnRange(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnDelimiter = ","
+	s := `1,"Smith, John",30`
+	if start, end := m.columnRange(s, 2); start != 9 || end != 15 {
+		t.Errorf("Document.columnRange() = %v, %v, want 9, 15", start, end)
+	}
+	m.ColumnCSV = true
+	if start, end := m.columnRange(s, 2); start != 16 || end != 18 {
+		t.Errorf("Document.columnRange() csv = %v, %v, want 16, 18", start, end)
+	}
+}
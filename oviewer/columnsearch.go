@@ -0,0 +1,154 @@
+package oviewer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// columnWord is a search restricted to a column.
+type columnWord struct {
+	searcher  Searcher
+	delimiter string
+	column    int
+	csv       bool
+	// bounds is the columns in ColumnWidth mode.
+	bounds []int
+}
+
+// columnWord Match matches the column of the line.
+func (substr columnWord) Match(s string) bool {
+	field, ok := substr.field(stripEscapeSequence(s))
+	if !ok {
+		return false
+	}
+	return substr.searcher.Match(field)
+}
+
+// field returns the column of the line.
+func (substr columnWord) field(s string) (string, bool) {
+	var start, end int
+	switch {
+	case substr.bounds != nil:
+		start, end = widthRangePosition(s, substr.bounds, substr.column)
+	case substr.csv:
+		start, end = csvRangePosition(s, substr.delimiter, substr.column)
+	default:
+		start, end = rangePosition(s, substr.delimiter, substr.column)
+	}
+	if start < 0 || end < 0 {
+		return "", false
+	}
+	return s[start:end], true
+}
+
+// columnSearch returns true if the search is restricted to the column highlighted in column mode.
+func (root *Root) columnSearch() bool {
+	m := root.Doc
+	return root.Config.ColumnSearch && m != nil && m.ColumnMode && (m.ColumnDelimiter != "" || m.ColumnWidth)
+}
+
+// columnSearcher restricts searcher to the highlighted column.
+// The fuzzy search and the multi-line search are not restricted.
+func (root *Root) columnSearcher(searcher Searcher) Searcher {
+	if !root.columnSearch() {
+		return searcher
+	}
+	switch searcher.(type) {
+	case fuzzyWord, multilineWord:
+		return searcher
+	}
+	m := root.Doc
+	return m.columnWord(searcher, m.originalColumn(m.columnNum))
+}
+
+// columnWord returns columnWord of the column of the original line with the current settings.
+// It can be used in the other goroutines without the document settings.
+func (m *Document) columnWord(searcher Searcher, column int) columnWord {
+	w := columnWord{
+		searcher:  searcher,
+		delimiter: m.ColumnDelimiter,
+		column:    column,
+		csv:       m.ColumnCSV,
+	}
+	if m.ColumnWidth {
+		w.bounds = m.columnBounds
+	}
+	return w
+}
+
+// columnPosition returns the positions of the search in the highlighted column.
+func (root *Root) columnPosition(lineStr string, position func(string) [][]int) [][]int {
+	start, end := root.Doc.columnRange(lineStr, root.Doc.columnNum)
+	if start < 0 || end < 0 {
+		return nil
+	}
+	poss := position(lineStr[start:end])
+	for _, p := range poss {
+		p[0] += start
+		p[1] += start
+	}
+	return poss
+}
+
+// headerNames returns the names of the columns in the header line (the first line after SkipLines).
+func (m *Document) headerNames() []string {
+	if m.ColumnDelimiter == "" && !m.ColumnWidth {
+		return nil
+	}
+	fields := m.lineFields(stripEscapeSequence(m.GetLine(m.SkipLines)))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, columnName(f))
+	}
+	return names
+}
+
+// columnName returns the name of the column without the spaces and the quotes.
+func columnName(field string) string {
+	name := strings.TrimSpace(field)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		name = strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return name
+}
+
+// headerColumn returns the number of the column of the header name.
+// A number is the column number starting from 1.
+func (m *Document) headerColumn(name string) (int, bool) {
+	for n, h := range m.headerNames() {
+		if strings.EqualFold(h, name) {
+			return n, true
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return n - 1, true
+	}
+	return 0, false
+}
+
+// setSearchColumn highlights the column of the header name (or the number)
+// and restricts the search to the column.
+// The empty input removes the restriction.
+func (root *Root) setSearchColumn(input string) {
+	if input == "" {
+		root.Config.ColumnSearch = false
+		root.setMessage("Search all columns")
+		return
+	}
+	m := root.Doc
+	n, ok := m.headerColumn(input)
+	if !ok {
+		root.setMessagef("%s: %s", ErrNoColumn, input)
+		return
+	}
+	m.ColumnMode = true
+	n = m.displayColumn(n)
+	if n < 0 {
+		root.setMessagef("%s: %s is hidden", ErrNoColumn, input)
+		return
+	}
+	m.columnNum = n
+	m.x = root.columnModeX()
+	root.Config.ColumnSearch = true
+	root.setMessagef("Search column %d", m.columnNum+1)
+}
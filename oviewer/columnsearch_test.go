@@ -0,0 +1,96 @@
+package oviewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_columnWord_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		column int
+		s      string
+		want   bool
+	}{
+		{name: "testMatch", column: 1, s: "2022-05-00,500,id500", want: true},
+		{name: "testOtherColumn", column: 1, s: "500,200,id1", want: false},
+		{name: "testLastColumn", column: 2, s: "1,200,id500", want: true},
+		{name: "testNoColumn", column: 3, s: "1,200,id500", want: false},
+		{name: "testEscapeSequence", column: 1, s: "1,\x1b[31m500\x1b[0m,id1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			substr := columnWord{
+				searcher:  searchWord{word: "500"},
+				delimiter: ",",
+				column:    tt.column,
+			}
+			if got := substr.Match(tt.s); got != tt.want {
+				t.Errorf("columnWord.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testColumnDocument(t *testing.T) *Document {
+	t.Helper()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader("time,status,bytes\n500,200,500\n501,500,12\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	m.ColumnDelimiter = ","
+	return m
+}
+
+func TestDocument_headerColumn(t *testing.T) {
+	m := testColumnDocument(t)
+	tests := []struct {
+		name   string
+		input  string
+		want   int
+		wantOK bool
+	}{
+		{name: "testName", input: "status", want: 1, wantOK: true},
+		{name: "testIgnoreCase", input: "Bytes", want: 2, wantOK: true},
+		{name: "testNumber", input: "1", want: 0, wantOK: true},
+		{name: "testNotFound", input: "size", want: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.headerColumn(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Document.headerColumn() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+	if got, want := m.headerNames(), []string{"time", "status", "bytes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.headerNames() = %v, want %v", got, want)
+	}
+}
+
+func TestRoot_columnSearch(t *testing.T) {
+	m := testColumnDocument(t)
+	m.ColumnMode = true
+	m.columnNum = 1
+	root := &Root{
+		Doc:    m,
+		input:  &Input{},
+		Config: Config{ColumnSearch: true},
+	}
+	searcher := root.setSearcher("500", false)
+	var got []bool
+	for n := 0; n < m.BufEndNum(); n++ {
+		got = append(got, m.matchLine(searcher, n))
+	}
+	if want := []bool{false, false, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Searcher.Match() = %v, want %v", got, want)
+	}
+	if got, want := root.searchPosition(2, "501,500,12"), [][]int{{4, 7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Root.searchPosition() = %v, want %v", got, want)
+	}
+}
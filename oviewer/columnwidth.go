@@ -0,0 +1,142 @@
+package oviewer
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// blankCells returns whether each cell of the line is blank.
+// A wide character occupies two cells.
+func blankCells(s string) []bool {
+	cells := make([]bool, 0, len(s))
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		for i := 0; i < w; i++ {
+			cells = append(cells, r == ' ')
+		}
+	}
+	return cells
+}
+
+// blankColumn returns true if the cell position is blank in all lines.
+// The end of a shorter line is regarded as blank.
+func blankColumn(lines [][]bool, p int) bool {
+	for _, cells := range lines {
+		if p < len(cells) && !cells[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// widthBounds returns the start cell positions of the columns.
+// The columns are the words of the header (the first line)
+// that are separated by the blank cells running through all lines.
+func widthBounds(lines []string) []int {
+	if len(lines) == 0 {
+		return nil
+	}
+	cellLines := make([][]bool, len(lines))
+	for i, line := range lines {
+		cellLines[i] = blankCells(line)
+	}
+
+	// The words of the header.
+	var words [][2]int
+	header := cellLines[0]
+	for p := 0; p < len(header); p++ {
+		if header[p] {
+			continue
+		}
+		start := p
+		for p < len(header) && !header[p] {
+			p++
+		}
+		words = append(words, [2]int{start, p})
+	}
+
+	bounds := []int{0}
+	for i := 1; i < len(words); i++ {
+		for p := words[i][0] - 1; p >= words[i-1][1]; p-- {
+			if blankColumn(cellLines, p) {
+				bounds = append(bounds, p+1)
+				break
+			}
+		}
+	}
+	return bounds
+}
+
+// widthRangePosition returns the range of the number-th column of the line split by the bounds.
+// The last column extends to the end of the line.
+// Returns -1, -1 if the line does not reach the column.
+func widthRangePosition(s string, bounds []int, number int) (int, int) {
+	if number < 0 || number >= len(bounds) {
+		return -1, -1
+	}
+	startCell := bounds[number]
+	endCell := -1
+	if number+1 < len(bounds) {
+		endCell = bounds[number+1]
+	}
+
+	start, end := -1, len(s)
+	cell := 0
+	for i, r := range s {
+		if start < 0 && cell >= startCell {
+			start = i
+		}
+		if endCell >= 0 && cell >= endCell {
+			end = i
+			break
+		}
+		cell += runewidth.RuneWidth(r)
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, end
+}
+
+// updateColumnBounds infers the columns from the header line (the first line after SkipLines)
+// and the lines below it in ColumnWidth mode.
+func (m *Document) updateColumnBounds() {
+	if !m.ColumnWidth || m.hexMode() {
+		return
+	}
+	endNum := m.BufEndNum()
+	limit := min(m.SkipLines+detectLines, endNum)
+	if m.columnBounds != nil && m.boundsScanned == limit {
+		return
+	}
+
+	lines := make([]string, 0, detectLines)
+	for lN := m.SkipLines; lN < limit; lN++ {
+		s := stripEscapeSequence(m.GetLine(lN))
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		lines = append(lines, s)
+	}
+	m.columnBounds = widthBounds(lines)
+	m.boundsScanned = limit
+}
+
+// toggleColumnWidth toggles ColumnWidth each time it is called.
+// Column mode is enabled with ColumnWidth.
+func (root *Root) toggleColumnWidth() {
+	m := root.Doc
+	m.ColumnWidth = !m.ColumnWidth
+	m.columnBounds = nil
+	m.updateColumnBounds()
+	if m.ColumnWidth {
+		m.ColumnMode = true
+	}
+	m.columnNum = 0
+	m.x = 0
+	root.setMessagef("Set ColumnWidth %t", m.ColumnWidth)
+}
@@ -0,0 +1,127 @@
+package oviewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_widthBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []int
+	}{
+		{
+			name: "testKubectl",
+			lines: []string{
+				"NAME      READY   STATUS",
+				"nginx-1   1/1     Running",
+				"redis     0/1     Pending",
+			},
+			want: []int{0, 10, 18},
+		},
+		{
+			name: "testRightAligned",
+			lines: []string{
+				"USER         PID %CPU COMMAND",
+				"root           1  0.0 /sbin/init splash",
+				"noborus    12345 12.5 ov -c",
+			},
+			want: []int{0, 11, 17, 22},
+		},
+		{
+			name: "testNoGap",
+			lines: []string{
+				"CONTAINER ID   IMAGE",
+				"a1b2c3d4e5f6   nginx",
+			},
+			want: []int{0, 15},
+		},
+		{
+			name: "testWide",
+			lines: []string{
+				"名前   値",
+				"あい   1",
+			},
+			want: []int{0, 7},
+		},
+		{
+			name:  "testEmpty",
+			lines: nil,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := widthBounds(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("widthBounds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_widthRangePosition(t *testing.T) {
+	bounds := []int{0, 10, 18}
+	tests := []struct {
+		name   string
+		s      string
+		number int
+		want   string
+	}{
+		{name: "testFirst", s: "nginx-1   1/1     Running", number: 0, want: "nginx-1   "},
+		{name: "testSecond", s: "nginx-1   1/1     Running", number: 1, want: "1/1     "},
+		{name: "testLast", s: "nginx-1   1/1     Running now", number: 2, want: "Running now"},
+		{name: "testWide", s: "あいう    1/1     Running", number: 1, want: "1/1     "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := widthRangePosition(tt.s, bounds, tt.number)
+			if start < 0 || end < 0 {
+				t.Fatalf("widthRangePosition() = %v, %v", start, end)
+			}
+			if got := tt.s[start:end]; got != tt.want {
+				t.Errorf("widthRangePosition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if start, end := widthRangePosition("nginx", bounds, 1); start != -1 || end != -1 {
+		t.Errorf("widthRangePosition() short = %v, %v, want -1, -1", start, end)
+	}
+	if start, end := widthRangePosition("nginx", bounds, 3); start != -1 || end != -1 {
+		t.Errorf("widthRangePosition() out of range = %v, %v, want -1, -1", start, end)
+	}
+}
+
+func TestDocument_updateColumnBounds(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := "NAME      READY   STATUS\nnginx-1   1/1     Running\nredis     0/1     Pending\n"
+	if err := m.ReadAll(strings.NewReader(str)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	m.ColumnWidth = true
+	m.updateColumnBounds()
+	if want := []int{0, 10, 18}; !reflect.DeepEqual(m.columnBounds, want) {
+		t.Fatalf("Document.updateColumnBounds() = %v, want %v", m.columnBounds, want)
+	}
+	if got := m.headerNames(); !reflect.DeepEqual(got, []string{"NAME", "READY", "STATUS"}) {
+		t.Errorf("Document.headerNames() = %v", got)
+	}
+
+	// The search is restricted to the column.
+	root := &Root{Doc: m, input: &Input{}, Config: Config{ColumnSearch: true}}
+	m.ColumnMode = true
+	m.columnNum = 2
+	searcher := root.columnSearcher(NewSearcher("Run", nil, false, false))
+	if !searcher.Match("nginx-1   1/1     Running") {
+		t.Errorf("columnWord.Match() = false, want true")
+	}
+	searcher = root.columnSearcher(NewSearcher("1/1", nil, false, false))
+	if searcher.Match("nginx-1   1/1     Running") {
+		t.Errorf("columnWord.Match() = true, want false")
+	}
+}
@@ -0,0 +1,171 @@
+package oviewer
+
+import (
+	"strings"
+)
+
+// detectLines is the number of lines from the top of the document to detect the delimiter.
+const detectLines = 30
+
+// delimiterCandidates is the delimiters to detect in order of priority.
+var delimiterCandidates = []struct {
+	delimiter string
+	name      string
+}{
+	{delimiter: ",", name: "comma"},
+	{delimiter: "\t", name: "tab"},
+	{delimiter: "|", name: "pipe"},
+	{delimiter: ";", name: "semicolon"},
+}
+
+// whitespaceName is the name of the whitespace-aligned columns.
+const whitespaceName = "whitespace"
+
+// detectDelimiter guesses the delimiter of the lines and returns the delimiter and its name.
+// The delimiter of the whitespace-aligned columns is empty.
+// Returns "", "" if the lines do not look like a table.
+func detectDelimiter(lines []string) (string, string) {
+	bestMatched, bestCount := 0, 0
+	delimiter, name := "", ""
+	for _, c := range delimiterCandidates {
+		count, matched := delimiterConsistency(lines, c.delimiter)
+		if count == 0 || matched < 2 || matched*5 < len(lines)*3 {
+			continue
+		}
+		if matched > bestMatched || (matched == bestMatched && count > bestCount) {
+			bestMatched, bestCount = matched, count
+			delimiter, name = c.delimiter, c.name
+		}
+	}
+	if name != "" {
+		return delimiter, name
+	}
+	if isWhitespaceAligned(lines) {
+		return "", whitespaceName
+	}
+	return "", ""
+}
+
+// delimiterConsistency returns the most frequent number of the delimiters in a line
+// and the number of the lines that have it.
+// The quoted delimiters are not counted.
+func delimiterConsistency(lines []string, delimiter string) (int, int) {
+	freq := make(map[int]int)
+	for _, line := range lines {
+		freq[countDelimiter(line, delimiter)]++
+	}
+	count, matched := 0, 0
+	for c, n := range freq {
+		if c == 0 {
+			continue
+		}
+		if n > matched || (n == matched && c > count) {
+			count, matched = c, n
+		}
+	}
+	return count, matched
+}
+
+// countDelimiter returns the number of the delimiters outside the quoted fields.
+func countDelimiter(s string, delimiter string) int {
+	count := 0
+	for start := 0; ; count++ {
+		end := csvFieldEnd(s, delimiter, start)
+		if end >= len(s) {
+			return count
+		}
+		start = end + len(delimiter)
+	}
+}
+
+// isWhitespaceAligned returns true if the lines have the columns aligned with spaces.
+// At least two gaps of spaces run through all lines, and one of them is two or more wide.
+func isWhitespaceAligned(lines []string) bool {
+	if len(lines) < 3 {
+		return false
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "\t") {
+			return false
+		}
+	}
+	gaps := whitespaceGaps(lines)
+	if len(gaps) < 2 {
+		return false
+	}
+	for _, g := range gaps {
+		if g[1]-g[0] >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// whitespaceGaps returns the ranges of the cell positions that are blank in all lines.
+// The end of a shorter line is regarded as blank.
+// The gaps before the first column and after the last column are not included.
+func whitespaceGaps(lines []string) [][2]int {
+	cellLines := make([][]bool, len(lines))
+	width := 0
+	for i, line := range lines {
+		cellLines[i] = blankCells(line)
+		width = max(width, len(cellLines[i]))
+	}
+
+	var gaps [][2]int
+	start := -1
+	for p := 0; p < width; p++ {
+		if blankColumn(cellLines, p) {
+			if start < 0 {
+				start = p
+			}
+			continue
+		}
+		if start > 0 {
+			gaps = append(gaps, [2]int{start, p})
+		}
+		start = -1
+	}
+	return gaps
+}
+
+// detectDelimiter detects the delimiter from the lines at the top of the document,
+// and enables column mode with it if the document looks like a table.
+// The detection is done once when enough lines are read.
+func (root *Root) detectDelimiter() {
+	m := root.Doc
+	if !m.DetectDelimiter || m.detected || m.hexMode() {
+		return
+	}
+	endNum := m.BufEndNum()
+	if !m.BufEOF() && endNum < m.SkipLines+detectLines {
+		return
+	}
+	m.detected = true
+	// Column mode enabled by the user is not changed.
+	if m.ColumnMode {
+		return
+	}
+
+	lines := make([]string, 0, detectLines)
+	for lN := m.SkipLines; lN < endNum && len(lines) < detectLines; lN++ {
+		s := stripEscapeSequence(m.GetLine(lN))
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		lines = append(lines, s)
+	}
+	delimiter, name := detectDelimiter(lines)
+	m.delimiterGuess = name
+	switch {
+	case name == whitespaceName:
+		m.ColumnWidth = true
+		m.updateColumnBounds()
+	case delimiter != "":
+		m.ColumnDelimiter = delimiter
+	default:
+		return
+	}
+	m.ColumnMode = true
+	root.setMessagef("Detected delimiter %s", name)
+}
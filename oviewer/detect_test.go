@@ -0,0 +1,156 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_detectDelimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		str           string
+		wantDelimiter string
+		wantName      string
+	}{
+		{
+			name:          "testCSV",
+			str:           "id,name,age\n1,\"Smith, John\",30\n2,Alice,4\n",
+			wantDelimiter: ",",
+			wantName:      "comma",
+		},
+		{
+			name:          "testTSV",
+			str:           "id\tname\n1\ta,b\n2\tc\n",
+			wantDelimiter: "\t",
+			wantName:      "tab",
+		},
+		{
+			name:          "testPsql",
+			str:           " id | name  \n----+-------\n  1 | Alice\n  2 | Bob\n(2 rows)\n",
+			wantDelimiter: "|",
+			wantName:      "pipe",
+		},
+		{
+			name:          "testSemicolon",
+			str:           "a;b;c\n1;2;3\n4;5;6\n",
+			wantDelimiter: ";",
+			wantName:      "semicolon",
+		},
+		{
+			name:          "testWhitespace",
+			str:           "NAME      READY   STATUS\nnginx-1   1/1     Running\nredis-2   0/1     Pending\n",
+			wantDelimiter: "",
+			wantName:      "whitespace",
+		},
+		{
+			name:          "testText",
+			str:           "This is a pen.\nHello, world.\nThe quick brown fox jumps over the lazy dog.\n",
+			wantDelimiter: "",
+			wantName:      "",
+		},
+		{
+			name:          "testOneLine",
+			str:           "a,b,c\n",
+			wantDelimiter: "",
+			wantName:      "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimSuffix(tt.str, "\n"), "\n")
+			gotDelimiter, gotName := detectDelimiter(lines)
+			if gotDelimiter != tt.wantDelimiter || gotName != tt.wantName {
+				t.Errorf("detectDelimiter() = %q, %q, want %q, %q", gotDelimiter, gotName, tt.wantDelimiter, tt.wantName)
+			}
+		})
+	}
+}
+
+func Test_whitespaceGaps(t *testing.T) {
+	lines := []string{
+		"NAME      READY   STATUS",
+		"nginx-1   1/1     Running",
+		"redis     0/1     Pending",
+	}
+	want := [][2]int{{7, 10}, {15, 18}}
+	got := whitespaceGaps(lines)
+	if len(got) != len(want) {
+		t.Fatalf("whitespaceGaps() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("whitespaceGaps() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRoot_detectDelimiter(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name          string
+		str           string
+		columnMode    bool
+		wantMode      bool
+		wantDelimiter string
+		wantGuess     string
+	}{
+		{
+			name:          "testDetect",
+			str:           "a|b|c\n1|2|3\n",
+			wantMode:      true,
+			wantDelimiter: "|",
+			wantGuess:     "pipe",
+		},
+		{
+			name:          "testColumnMode",
+			str:           "a|b|c\n1|2|3\n",
+			columnMode:    true,
+			wantMode:      true,
+			wantDelimiter: ",",
+			wantGuess:     "",
+		},
+		{
+			name:          "testWhitespace",
+			str:           "NAME      READY   STATUS\nnginx-1   1/1     Running\nredis-2   0/1     Pending\n",
+			wantMode:      true,
+			wantDelimiter: ",",
+			wantGuess:     "whitespace",
+		},
+		{
+			name:          "testNotTable",
+			str:           "abc\ndef\n",
+			wantMode:      false,
+			wantDelimiter: ",",
+			wantGuess:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ReadAll(strings.NewReader(tt.str)); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			root, err := NewOviewer(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.DetectDelimiter = true
+			m.ColumnMode = tt.columnMode
+			m.ColumnDelimiter = ","
+			root.detectDelimiter()
+			if m.ColumnMode != tt.wantMode || m.ColumnDelimiter != tt.wantDelimiter || m.delimiterGuess != tt.wantGuess {
+				t.Errorf("Root.detectDelimiter() = %v, %q, %q, want %v, %q, %q",
+					m.ColumnMode, m.ColumnDelimiter, m.delimiterGuess, tt.wantMode, tt.wantDelimiter, tt.wantGuess)
+			}
+		})
+	}
+}
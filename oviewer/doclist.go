@@ -31,6 +31,7 @@ func (root *Root) addDocument(m *Document) {
 	root.setMessagef("add %s", m.FileName)
 	m.general = root.Config.General
 	m.setSectionDelimiter(m.SectionDelimiter)
+	m.setHexMode()
 
 	root.mu.Lock()
 	root.DocList = append(root.DocList, m)
@@ -54,6 +55,19 @@ func (root *Root) closeDocument() {
 	if err := root.DocList[root.CurrentDoc].close(); err != nil {
 		log.Printf("%s:%s", root.Doc.FileName, err)
 	}
+	if err := root.DocList[root.CurrentDoc].closeIndex(); err != nil {
+		log.Printf("%s:%s", root.Doc.FileName, err)
+	}
+	if err := root.DocList[root.CurrentDoc].closeHex(); err != nil {
+		log.Printf("%s:%s", root.Doc.FileName, err)
+	}
+	root.DocList[root.CurrentDoc].stopCountMatch()
+	// Stop filtering the closed document.
+	for _, doc := range root.DocList {
+		if doc == root.DocList[root.CurrentDoc] || (doc.filter != nil && doc.filter.parent == root.DocList[root.CurrentDoc]) {
+			doc.stopFilter()
+		}
+	}
 	root.DocList = append(root.DocList[:root.CurrentDoc], root.DocList[root.CurrentDoc+1:]...)
 	if root.CurrentDoc > 0 {
 		root.CurrentDoc--
@@ -64,6 +78,18 @@ func (root *Root) closeDocument() {
 	root.setDocument(doc)
 }
 
+// docNum returns the number of the document in DocList, or -1 if not found.
+func (root *Root) docNum(m *Document) int {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for n, doc := range root.DocList {
+		if doc == m {
+			return n
+		}
+	}
+	return -1
+}
+
 // nextDoc displays the next document.
 func (root *Root) nextDoc() {
 	root.setDocumentNum(root.CurrentDoc + 1)
@@ -103,7 +129,13 @@ func (root *Root) setDocumentNum(docNum int) {
 
 // setDocument sets the Document.
 func (root *Root) setDocument(m *Document) {
+	// The matches of the hidden document are not counted.
+	if root.Doc != nil && root.Doc != m {
+		root.Doc.stopCountMatch()
+	}
 	root.Doc = m
+	m.closeTail()
+	root.countMatch()
 	if m.WatchMode {
 		root.watchStart()
 	}
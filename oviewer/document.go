@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"log"
 	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
+	"golang.org/x/text/encoding"
 )
 
 // The Document structure contains the values
@@ -27,6 +29,11 @@ type Document struct {
 	offset int64
 	// CFormat is a compressed format.
 	CFormat Compressed
+	// encoding is the character encoding to convert to UTF-8.
+	// nil if it is UTF-8.
+	encoding encoding.Encoding
+	// encodingName is the name of the encoding being converted.
+	encodingName string
 
 	// preventReload is true to prevent reload.
 	preventReload bool
@@ -36,6 +43,20 @@ type Document struct {
 	// lines stores the contents of the file in slices of strings.
 	// lines,endNum and eof is updated by reader goroutine.
 	lines []string
+	// index is the line index of a seekable file.
+	// If index is not nil, the lines are read from the file instead of lines.
+	index *lineIndex
+	// tail is the last lines of the file displayed before the index reaches the end.
+	tail *lineTail
+	// archive is the members of the archive if the document is a listing of the archive.
+	archive *archive
+	// matches is the lines that match the search counted in the background.
+	matches *searchMatches
+	// filter is the parent document if the document is filtered.
+	filter *filter
+	// hex is the raw bytes displayed in hex mode.
+	// If hex is not nil, the rows of the hex dump are displayed instead of the lines.
+	hex *hexDump
 	// endNum is the number of the last line read.
 	endNum int
 
@@ -56,6 +77,10 @@ type Document struct {
 	cancel context.CancelFunc
 	// 1 if there is a closed.
 	closed int32
+	// 1 if the file has been rotated in follow mode.
+	rotated int32
+	// 1 if the file has been truncated and the document has not yet been redisplayed.
+	truncated int32
 
 	// cache represents a cache of contents.
 	cache *ristretto.Cache
@@ -86,6 +111,21 @@ type Document struct {
 	x int
 	// columnNum is the number of columns.
 	columnNum int
+	// align is the widths of the columns in align mode.
+	align alignColumns
+	// detected is true if the delimiter has been detected.
+	detected bool
+	// delimiterGuess is the name of the detected delimiter.
+	delimiterGuess string
+	// columnBounds is the start positions of the columns in ColumnWidth mode.
+	columnBounds []int
+	// boundsScanned is the line number up to which the columnBounds is inferred.
+	boundsScanned int
+	// layout is the column numbers of the original line in the displayed order.
+	// nil displays all columns.
+	layout []int
+	// layoutKey is the ColumnLayout that the layout is set by.
+	layoutKey string
 
 	// marked is a list of marked line numbers.
 	marked      []int
@@ -164,12 +204,109 @@ func STDINDocument() (*Document, error) {
 // GetLine returns one line from buffer.
 func (m *Document) GetLine(n int) string {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	if n < 0 || n >= m.endNum {
+		m.mu.Unlock()
+		return ""
+	}
+	indexNum := m.indexNum()
+	if n >= indexNum {
+		line := m.lines[n-indexNum]
+		m.mu.Unlock()
+		return line
+	}
+
+	// Read from the file without holding the lock.
+	index := m.index
+	cn, chunk := index.chunk(n)
+	m.mu.Unlock()
+	line, err := index.line(cn, chunk, n)
+	if err != nil {
+		log.Printf("GetLine %d: %s", n, err)
 		return ""
 	}
-	return m.lines[n]
+	return line
+}
+
+// indexing returns true if the lines read are added to the line index.
+func (m *Document) indexing() bool {
+	return m.index != nil && !m.index.sealed
+}
+
+// indexNum returns the number of lines in the line index.
+// The lines after the line index are stored in lines.
+func (m *Document) indexNum() int {
+	if m.index == nil {
+		return 0
+	}
+	return m.index.lineNum()
+}
+
+// openIndex opens the line index of the file.
+// The line index is used for seekable files that are uncompressed
+// or compressed in a format that can be read from a checkpoint,
+// other documents store the lines in memory.
+func (m *Document) openIndex(fileName string) error {
+	if m.index != nil {
+		if err := m.index.close(); err != nil {
+			log.Printf("openIndex: %s", err)
+		}
+		m.index = nil
+	}
+	// Continue in memory if the lines are already stored in memory.
+	if m.endNum > 0 {
+		return nil
+	}
+	if fileName == "" || !m.seekable {
+		return nil
+	}
+	if m.CFormat != UNCOMPRESSED && !seekFormat(m.CFormat) {
+		return nil
+	}
+
+	index, err := newLineIndex(fileName, m.CFormat)
+	if err != nil {
+		return err
+	}
+	m.index = index
+	return nil
+}
+
+// loadIndex reads all lines of the line index into memory
+// and stops using the line index.
+func (m *Document) loadIndex() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.index == nil {
+		return nil
+	}
+
+	lines := make([]string, 0, m.endNum)
+	for n := 0; n < m.index.lineNum(); n++ {
+		cn, chunk := m.index.chunk(n)
+		line, err := m.index.line(cn, chunk, n)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+	m.lines = append(lines, m.lines...)
+	return m.closeIndex()
+}
+
+// closeIndex closes the line index.
+// The tail is also closed because it is replaced by the line index.
+func (m *Document) closeIndex() error {
+	if m.index == nil {
+		return nil
+	}
+	err := m.index.close()
+	m.index = nil
+	if m.tail != nil {
+		m.tail = nil
+		m.ClearCache()
+	}
+	return err
 }
 
 // CurrentLN returns the currently displayed line number.
@@ -180,10 +317,10 @@ func (m *Document) CurrentLN() int {
 // Export exports the document in the specified range.
 func (m *Document) Export(w io.Writer, start int, end int) {
 	for n := start; n <= end; n++ {
-		if n >= m.BufEndNum() {
+		if n >= m.viewEndNum() {
 			break
 		}
-		fmt.Fprintln(w, m.GetLine(n))
+		fmt.Fprintln(w, m.viewLine(n))
 	}
 }
 
@@ -194,6 +331,63 @@ func (m *Document) BufEndNum() int {
 	return m.endNum
 }
 
+// viewLine returns line n displayed on the screen.
+// In hex mode, it is row n of the hex dump.
+// While displaying the tail, the lines after SkipLines and Header are the lines of the tail.
+// The line numbers of the screen are used by the movement, the search and the marks,
+// and the other features read the lines of the document by GetLine.
+func (m *Document) viewLine(n int) string {
+	head := m.firstLine()
+	m.mu.Lock()
+	if m.hex != nil {
+		m.mu.Unlock()
+		return m.hexLine(n)
+	}
+	if m.tail != nil && n >= head {
+		line := ""
+		if n-head < len(m.tail.lines) {
+			line = m.tail.lines[n-head]
+		}
+		m.mu.Unlock()
+		return line
+	}
+	m.mu.Unlock()
+	return m.GetLine(n)
+}
+
+// viewEndNum returns the number of lines displayed on the screen.
+func (m *Document) viewEndNum() int {
+	m.mu.Lock()
+	if m.hex != nil {
+		n := m.hexRowNum()
+		m.mu.Unlock()
+		return n
+	}
+	if m.tail != nil {
+		n := m.firstLine() + len(m.tail.lines)
+		m.mu.Unlock()
+		return n
+	}
+	m.mu.Unlock()
+	return m.BufEndNum()
+}
+
+// viewOverlaid returns true if the lines displayed on the screen
+// are not the lines of the document.
+func (m *Document) viewOverlaid() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hex != nil || m.tail != nil
+}
+
+// matchView returns true if line n displayed on the screen matches searcher.
+func (m *Document) matchView(searcher Searcher, n int) bool {
+	if m.viewOverlaid() {
+		return searcher.Match(m.viewLine(n))
+	}
+	return m.matchLine(searcher, n)
+}
+
 // BufEOF return true if EOF is reached.
 func (m *Document) BufEOF() bool {
 	return atomic.LoadInt32(&m.eof) == 1
@@ -220,11 +414,15 @@ func (m *Document) ClearCache() {
 
 // contentsLN returns contents from line number and tabwidth.
 func (m *Document) contentsLN(lN int, tabWidth int) (contents, error) {
-	if lN < 0 || lN >= m.BufEndNum() {
+	if lN < 0 || lN >= m.viewEndNum() {
 		return nil, ErrOutOfRange
 	}
 
 	key := fmt.Sprintf("contents:%d", lN)
+	align := m.alignEnabled()
+	if align {
+		key = fmt.Sprintf("align:%d:%d", m.align.version, lN)
+	}
 	if value, found := m.cache.Get(key); found {
 		// It was cached.
 		lc, ok := value.(contents)
@@ -235,7 +433,10 @@ func (m *Document) contentsLN(lN int, tabWidth int) (contents, error) {
 	}
 
 	// It wasn't cached.
-	str := m.GetLine(lN)
+	str := m.viewLine(lN)
+	if align {
+		str, _ = m.alignLine(str)
+	}
 	lc := parseString(str, tabWidth)
 	m.cache.Set(key, lc, 1)
 	return lc, nil
@@ -275,38 +476,33 @@ func (m *Document) firstLine() int {
 }
 
 // SearchLine searches the document and returns the matching line.
+// The lines are searched in parallel and the nearest matching line from num is returned.
+// While the tail or the hex dump is displayed, the displayed lines are searched.
 func (m *Document) SearchLine(ctx context.Context, searcher Searcher, num int) (int, error) {
 	num = max(num, 0)
-
-	for n := num; n < m.BufEndNum(); n++ {
-		if searcher.Match(m.GetLine(n)) {
-			return n, nil
-		}
-		select {
-		case <-ctx.Done():
-			return 0, ErrCancel
-		default:
-		}
+	if w, ok := searcher.(hexWord); ok && m.hexMode() {
+		return m.searchBytes(ctx, w.pattern, num, true)
 	}
 
-	return 0, ErrNotFound
+	if m.viewOverlaid() {
+		return m.searchView(ctx, searcher, num, true)
+	}
+	return m.searchParallel(ctx, searcher, num, true)
 }
 
 // BackSearchLine does a backward search on the document and returns a matching line.
+// The lines are searched in parallel and the nearest matching line from num is returned.
+// While the tail or the hex dump is displayed, the displayed lines are searched.
 func (m *Document) BackSearchLine(ctx context.Context, searcher Searcher, num int) (int, error) {
-	num = min(num, m.BufEndNum()-1)
+	num = min(num, m.viewEndNum()-1)
+	if w, ok := searcher.(hexWord); ok && m.hexMode() {
+		return m.searchBytes(ctx, w.pattern, num, false)
+	}
 
-	for n := num; n >= 0; n-- {
-		if searcher.Match(m.GetLine(n)) {
-			return n, nil
-		}
-		select {
-		case <-ctx.Done():
-			return 0, ErrCancel
-		default:
-		}
+	if m.viewOverlaid() {
+		return m.searchView(ctx, searcher, num, false)
 	}
-	return 0, ErrNotFound
+	return m.searchParallel(ctx, searcher, num, false)
 }
 
 func (m *Document) watchMode() {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -21,6 +22,8 @@ func (root *Root) draw() {
 		return
 	}
 
+	root.updateColumns()
+
 	// Header
 	lY := root.drawHeader()
 
@@ -45,6 +48,64 @@ func (root *Root) draw() {
 	root.Show()
 }
 
+// columnsKey is the state that the columns are computed with.
+type columnsKey struct {
+	doc          *Document
+	delimiter    string
+	csv          bool
+	columnMode   bool
+	columnWidth  bool
+	alignMode    bool
+	hexMode      bool
+	detect       bool
+	layout       string
+	alignVersion int
+	skipLines    int
+	header       int
+	topLN        int
+	height       int
+	endNum       int
+	eof          bool
+}
+
+// updateColumns detects the delimiter and updates the bounds, the layout and the widths of the columns.
+// They are computed again only when the document, the delimiter or the displayed range changes.
+func (root *Root) updateColumns() {
+	m := root.Doc
+	key := columnsKey{
+		doc:          m,
+		delimiter:    m.ColumnDelimiter,
+		csv:          m.ColumnCSV,
+		columnMode:   m.ColumnMode,
+		columnWidth:  m.ColumnWidth,
+		alignMode:    m.AlignMode,
+		hexMode:      m.HexMode,
+		detect:       m.DetectDelimiter,
+		layout:       strings.Join(m.ColumnLayout, ","),
+		alignVersion: m.align.version,
+		skipLines:    m.SkipLines,
+		header:       m.Header,
+		topLN:        m.topLN,
+		height:       root.vHight,
+		endNum:       m.viewEndNum(),
+		eof:          m.BufEOF(),
+	}
+	if key == root.columnsKey {
+		return
+	}
+
+	root.detectDelimiter()
+	m.updateColumnBounds()
+	m.updateLayout()
+	m.updateAlign(m.topLN, root.vHight)
+	// The detection and the widths may change the state.
+	key.delimiter = m.ColumnDelimiter
+	key.columnMode = m.ColumnMode
+	key.columnWidth = m.ColumnWidth
+	key.alignVersion = m.align.version
+	root.columnsKey = key
+}
+
 // drawHeader draws header.
 func (root *Root) drawHeader() int {
 	m := root.Doc
@@ -116,6 +177,7 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 		}
 
 		root.columnHighlight(lc, lineStr, posCV)
+		root.multiHighlight(lc, lineStr, posCV)
 		root.searchHighlight(lY, lc, lineStr, posCV)
 		root.drawLineNumber(lY, y)
 
@@ -179,7 +241,17 @@ func (root *Root) drawNoWrapLine(y int, lX int, lY int, lc contents) (int, int)
 		lX = root.minStartX
 	}
 
+	// The pinned columns are drawn at the left edge.
+	pinned := 0
+	if lX > 0 {
+		pinned = root.pinnedWidth(lc)
+	}
 	for x := 0; root.startX+x < root.vWidth; x++ {
+		if x < pinned {
+			content := lc[x]
+			root.Screen.SetContent(root.startX+x, y, content.mainc, content.combc, content.style)
+			continue
+		}
 		if lX+x >= len(lc) {
 			// EOL
 			root.clearEOL(root.startX+x, y)
@@ -233,6 +305,10 @@ func (root *Root) drawLineNumber(lY int, y int) {
 		return
 	}
 	// Line numbers start at 1 except for skip and header lines.
+	// The filtered document displays the line numbers of the parent document.
+	if lN, ok := m.originLine(lY); ok {
+		lY = lN
+	}
 	numC := StrToContents(fmt.Sprintf("%*d", root.startX-1, lY-m.firstLine()+1), m.TabWidth)
 	for i := 0; i < len(numC); i++ {
 		numC[i].style = applyStyle(tcell.StyleDefault, root.StyleLineNumber)
@@ -245,7 +321,7 @@ func (root *Root) columnHighlight(lc contents, str string, posCV map[int]int) {
 	if !root.Doc.ColumnMode {
 		return
 	}
-	start, end := rangePosition(str, root.Doc.ColumnDelimiter, root.Doc.columnNum)
+	start, end := root.Doc.columnRange(str, root.Doc.columnNum)
 	RangeStyle(lc, posCV[start], posCV[end], root.StyleColumnHighlight)
 }
 
@@ -324,12 +400,30 @@ func (root *Root) normalLeftStatus() (contents, int) {
 	} else if root.Doc.FollowSection {
 		modeStatus = "(Follow Section)"
 	}
+	if atomic.LoadInt32(&root.Doc.rotated) == 1 {
+		modeStatus += "(Rotated)"
+	}
 	caption := root.Doc.FileName
 	if root.Doc.Caption != "" {
 		caption = root.Doc.Caption
 	}
 
-	leftStatus := fmt.Sprintf("%s%s%s:%s", number, modeStatus, caption, root.message)
+	format := ""
+	if root.Doc.CFormat != UNCOMPRESSED {
+		format += "(" + root.Doc.CFormat.String() + ")"
+	}
+	if archive := root.Doc.ArchiveFormat(); archive != NOARCHIVE {
+		format += "(" + archive.String() + ")"
+	}
+	if encoding := root.Doc.EncodingName(); encoding != "" {
+		format += "(" + encoding + ")"
+	}
+	format += root.Doc.hexStatus()
+	if root.Doc.delimiterGuess != "" {
+		format += "(" + root.Doc.delimiterGuess + ")"
+	}
+
+	leftStatus := fmt.Sprintf("%s%s%s%s:%s", number, modeStatus, caption, format, root.message)
 	leftContents := StrToContents(leftStatus, -1)
 	color := tcell.ColorWhite
 	if root.CurrentDoc != 0 {
@@ -344,15 +438,35 @@ func (root *Root) normalLeftStatus() (contents, int) {
 func (root *Root) inputLeftStatus() (contents, int) {
 	input := root.input
 	searchMode := ""
-	if input.mode == Search || input.mode == Backsearch {
+	if input.mode == Search || input.mode == Backsearch || input.mode == SearchAll || input.mode == Filter || input.mode == Occur {
 		if root.Config.RegexpSearch {
 			searchMode += "(R)"
 		}
-		if root.Config.Incsearch {
+		if root.Config.WildcardSearch {
+			searchMode += "(*)"
+		}
+		if root.Config.WholeWord {
+			searchMode += "(W)"
+		}
+		if root.Config.MultilineSearch {
+			searchMode += "(M)"
+		}
+		if root.Config.FuzzySearch {
+			searchMode += "(F)"
+		}
+		if root.columnSearch() {
+			searchMode += fmt.Sprintf("(C%d)", root.Doc.columnNum+1)
+		}
+		if root.Config.Incsearch && (input.mode == Search || input.mode == Backsearch) {
 			searchMode += "(I)"
 		}
+	}
+	// The highlight is always a regular expression.
+	if input.mode == Search || input.mode == Backsearch || input.mode == SearchAll || input.mode == Filter || input.mode == Occur || input.mode == Highlight {
 		if root.CaseSensitive {
 			searchMode += "(Aa)"
+		} else if root.Config.SmartCase {
+			searchMode += "(Sc)"
 		}
 	}
 	p := searchMode + input.EventInput.Prompt()
@@ -366,7 +480,12 @@ func (root *Root) rightStatus() contents {
 	if !root.Doc.BufEOF() {
 		next = "..."
 	}
-	str := fmt.Sprintf("(%d/%d%s)", root.Doc.topLN, root.Doc.BufEndNum(), next)
+	str := fmt.Sprintf("(%d/%d%s)", root.Doc.topLN, root.Doc.viewEndNum(), next)
+	// The line numbers are estimated until the line index reaches the end.
+	if start, end, ok := root.Doc.tailStatus(); ok {
+		str = fmt.Sprintf("(~%d/~%d%s)", start+root.Doc.topLN, end, next)
+	}
+	str = root.matchStatus() + str
 	return StrToContents(str, -1)
 }
 
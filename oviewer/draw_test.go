@@ -0,0 +1,50 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_updateColumns(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader("id,name\n1,Alice\n2,Bob\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.vHight = 10
+	m.DetectDelimiter = true
+	m.AlignMode = true
+
+	root.updateColumns()
+	if !m.ColumnMode || len(m.align.widths) != 2 {
+		t.Fatalf("Root.updateColumns() = %v, %v, want true, 2 widths", m.ColumnMode, m.align.widths)
+	}
+
+	// The columns are not computed again in the same state.
+	m.align.widths = nil
+	root.updateColumns()
+	if m.align.widths != nil {
+		t.Errorf("Root.updateColumns() widths = %v, want nil", m.align.widths)
+	}
+
+	// The columns are computed again when the displayed range changes.
+	m.align.sampled = [3]int{}
+	m.topLN = 1
+	root.updateColumns()
+	if len(m.align.widths) != 2 {
+		t.Errorf("Root.updateColumns() widths = %v, want 2 widths", m.align.widths)
+	}
+}
@@ -0,0 +1,114 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// DefaultEncoding is the character encoding of the input
+// when it cannot be detected by BOM.
+// It is used for documents whose Encoding is not set.
+// Empty is UTF-8.
+var DefaultEncoding = ""
+
+// boms is a list of BOMs and encodings that can be detected.
+// UTF-32LE must be checked before UTF-16LE.
+var boms = []struct {
+	bom  []byte
+	name string
+	enc  encoding.Encoding
+}{
+	{[]byte{0x00, 0x00, 0xfe, 0xff}, "UTF-32BE", utf32.UTF32(utf32.BigEndian, utf32.UseBOM)},
+	{[]byte{0xff, 0xfe, 0x00, 0x00}, "UTF-32LE", utf32.UTF32(utf32.LittleEndian, utf32.UseBOM)},
+	{[]byte{0xfe, 0xff}, "UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.UseBOM)},
+	{[]byte{0xff, 0xfe}, "UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)},
+}
+
+// detectEncoding returns the encoding detected from the BOM of the header.
+func detectEncoding(header []byte) (string, encoding.Encoding) {
+	for _, b := range boms {
+		if bytes.HasPrefix(header, b.bom) {
+			return b.name, b.enc
+		}
+	}
+	return "", nil
+}
+
+// lookupEncoding returns the encoding of the name.
+// nil is returned for UTF-8, which does not need to be converted.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8":
+		return nil, nil
+	}
+
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err == nil && enc != nil {
+		return enc, nil
+	}
+	enc, err = htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+	}
+	return enc, nil
+}
+
+// decodeReader returns a reader that converts to UTF-8.
+// The encoding is detected by BOM, otherwise the encoding of name is used.
+// Lines of the converted document are not read from the file,
+// so the line index is not used.
+func (m *Document) decodeReader(reader *bufio.Reader, name string) *bufio.Reader {
+	header, _ := reader.Peek(4)
+	bomName, enc := detectEncoding(header)
+	if enc == nil {
+		var err error
+		enc, err = lookupEncoding(name)
+		if err != nil {
+			log.Printf("decodeReader: %s", err)
+		}
+		bomName = name
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.encoding = enc
+	if enc == nil {
+		m.encodingName = ""
+		return reader
+	}
+	m.encodingName = bomName
+	if err := m.closeIndex(); err != nil {
+		log.Printf("decodeReader: %s", err)
+	}
+	return bufio.NewReader(enc.NewDecoder().Reader(reader))
+}
+
+// continueDecodeReader returns a reader that converts to UTF-8
+// with the encoding determined at the beginning.
+func (m *Document) continueDecodeReader(r io.Reader) io.Reader {
+	m.mu.Lock()
+	enc := m.encoding
+	m.mu.Unlock()
+	if enc == nil {
+		return r
+	}
+	return enc.NewDecoder().Reader(r)
+}
+
+// EncodingName returns the name of the encoding being converted.
+// Empty if it is UTF-8.
+func (m *Document) EncodingName() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.encodingName
+}
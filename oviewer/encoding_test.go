@@ -0,0 +1,182 @@
+package oviewer
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+func Test_detectEncoding(t *testing.T) {
+	type args struct {
+		header []byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "testUTF8",
+			args: args{header: []byte("test")},
+			want: "",
+		},
+		{
+			name: "testUTF16LE",
+			args: args{header: []byte{0xff, 0xfe, 0x74, 0x00}},
+			want: "UTF-16LE",
+		},
+		{
+			name: "testUTF16BE",
+			args: args{header: []byte{0xfe, 0xff, 0x00, 0x74}},
+			want: "UTF-16BE",
+		},
+		{
+			name: "testUTF32LE",
+			args: args{header: []byte{0xff, 0xfe, 0x00, 0x00}},
+			want: "UTF-32LE",
+		},
+		{
+			name: "testUTF32BE",
+			args: args{header: []byte{0x00, 0x00, 0xfe, 0xff}},
+			want: "UTF-32BE",
+		},
+		{
+			name: "testShort",
+			args: args{header: []byte{0xff}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := detectEncoding(tt.args.header); got != tt.want {
+				t.Errorf("detectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lookupEncoding(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			name:    "testEmpty",
+			args:    args{name: ""},
+			wantNil: true,
+			wantErr: false,
+		},
+		{
+			name:    "testUTF8",
+			args:    args{name: "UTF-8"},
+			wantNil: true,
+			wantErr: false,
+		},
+		{
+			name:    "testShiftJIS",
+			args:    args{name: "Shift_JIS"},
+			wantNil: false,
+			wantErr: false,
+		},
+		{
+			name:    "testEUCJP",
+			args:    args{name: "euc-jp"},
+			wantNil: false,
+			wantErr: false,
+		},
+		{
+			name:    "testUnknown",
+			args:    args{name: "unknown-encoding"},
+			wantNil: true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupEncoding(tt.args.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lookupEncoding() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("lookupEncoding() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestDocument_ReadAllEncoding(t *testing.T) {
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String("テスト\r\nline2\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf32be, err := utf32.UTF32(utf32.BigEndian, utf32.UseBOM).NewEncoder().String("テスト\nline2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sjis, err := japanese.ShiftJIS.NewEncoder().String("テスト\nline2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		str      string
+		encoding string
+		wantName string
+	}{
+		{
+			name:     "testUTF8",
+			str:      "テスト\nline2\n",
+			encoding: "",
+			wantName: "",
+		},
+		{
+			name:     "testUTF16BOM",
+			str:      utf16,
+			encoding: "",
+			wantName: "UTF-16LE",
+		},
+		{
+			name:     "testUTF32BOM",
+			str:      utf32be,
+			encoding: "Shift_JIS",
+			wantName: "UTF-32BE",
+		},
+		{
+			name:     "testShiftJIS",
+			str:      sjis,
+			encoding: "Shift_JIS",
+			wantName: "Shift_JIS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Encoding = tt.encoding
+			if err := m.ReadAll(bytes.NewBufferString(tt.str)); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			if got := m.EncodingName(); got != tt.wantName {
+				t.Errorf("Document.EncodingName() = %v, want %v", got, tt.wantName)
+			}
+			if got := m.GetLine(0); got != "テスト" {
+				t.Errorf("Document.GetLine(0) = %v, want %v", got, "テスト")
+			}
+			if got := m.GetLine(1); got != "line2" {
+				t.Errorf("Document.GetLine(1) = %v, want %v", got, "line2")
+			}
+		})
+	}
+}
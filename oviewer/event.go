@@ -65,11 +65,28 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 		case *viewModeInput:
 			root.setViewMode(ev.value)
 		case *searchInput:
+			root.searchAll = false
+			root.fuzzy = nil
 			searcher := root.setSearcher(root.input.value, root.CaseSensitive)
 			root.searchMove(ctx, true, root.Doc.topLN+root.Doc.firstLine(), searcher)
+		case *searchAllInput:
+			searcher := root.setSearcher(root.input.value, root.CaseSensitive)
+			root.searchAllDocs(ctx, searcher)
 		case *backSearchInput:
+			root.searchAll = false
+			root.fuzzy = nil
 			searcher := root.setSearcher(root.input.value, root.CaseSensitive)
 			root.searchMove(ctx, false, root.Doc.topLN+root.Doc.firstLine(), searcher)
+		case *filterInput:
+			root.filter(ctx, ev.value)
+		case *searchColumnInput:
+			root.setSearchColumn(ev.value)
+		case *occurInput:
+			root.occur(ctx, ev.value)
+		case *sortInput:
+			root.sortColumn(ctx, ev.value)
+		case *highlightInput:
+			root.toggleHighlight(ev.value)
 		case *gotoInput:
 			root.goLine(ev.value)
 		case *headerInput:
@@ -178,11 +195,13 @@ func (root *Root) follow() {
 
 	root.Doc.onceFollowMode()
 
-	num := root.Doc.BufEndNum()
+	num := root.Doc.viewEndNum()
 	if root.Doc.latestNum == num {
 		return
 	}
 
+	// Resume counting the matches if it has stopped before following.
+	root.countMatch()
 	root.skipDraw = false
 	if root.Doc.FollowSection {
 		root.tailSection()
@@ -204,7 +223,7 @@ func (root *Root) followAll() {
 	root.mu.RLock()
 	for n, doc := range root.DocList {
 		doc.onceFollowMode()
-		if doc.latestNum != doc.BufEndNum() {
+		if doc.latestNum != doc.viewEndNum() {
 			current = n
 		}
 	}
@@ -268,7 +287,7 @@ func (root *Root) MoveTop() {
 
 // MoveBottom fires the event of moving to bottom.
 func (root *Root) MoveBottom() {
-	root.MoveLine(root.Doc.BufEndNum())
+	root.MoveLine(root.Doc.viewEndNum())
 }
 
 // eventSearch represents search event.
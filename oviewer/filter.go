@@ -0,0 +1,177 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// filterInterval is the interval to check the lines added to the parent document.
+var filterInterval = 100 * time.Millisecond
+
+// filter represents the parent of the document that contains
+// only the lines of the parent document that match the pattern,
+// or the lines of the parent document sorted by SortDocument.
+type filter struct {
+	// parent is the filtered document.
+	parent *Document
+	// searcher matches the lines of the parent document.
+	searcher Searcher
+	// invert is true to contain the lines that do not match.
+	invert bool
+	// header is the number of lines that are contained regardless of the match.
+	header int
+	// occur is true to prefix the lines with the line numbers as the search results.
+	occur bool
+	// lineNums is the line number of the parent document of each line.
+	lineNums []int
+}
+
+// FilterDocument returns a Document that contains only the lines that match searcher.
+// If invert is true, the lines that do not match are contained.
+// The lines added to m (as in follow mode) are filtered until ctx is done
+// or the returned Document is closed.
+func (m *Document) FilterDocument(ctx context.Context, searcher Searcher, invert bool) (*Document, error) {
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = m.FileName + ":filter"
+	doc.filter = &filter{
+		parent:   m,
+		searcher: searcher,
+		invert:   invert,
+		header:   m.firstLine(),
+	}
+	doc.startFilter(ctx)
+	return doc, nil
+}
+
+// OccurDocument returns a Document that lists the lines that match searcher
+// prefixed with the line numbers, as the search results.
+// The line number is the number displayed in the line number mode of m.
+// The header lines of m are contained without the line numbers.
+func (m *Document) OccurDocument(ctx context.Context, searcher Searcher) (*Document, error) {
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = m.FileName + ":occur"
+	doc.filter = &filter{
+		parent:   m,
+		searcher: searcher,
+		header:   m.firstLine(),
+		occur:    true,
+	}
+	doc.startFilter(ctx)
+	return doc, nil
+}
+
+// startFilter starts filtering the parent document.
+func (m *Document) startFilter(ctx context.Context) {
+	m.seekable = false
+	m.preventReload = true
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	go m.filterLines(ctx)
+}
+
+// isOccur returns true if the document is the search results.
+func (m *Document) isOccur() bool {
+	return m.filter != nil && m.filter.occur
+}
+
+// filterLines adds the matching lines of the parent document.
+// If the parent document is reset (reload or truncated), it is filtered again from the beginning.
+func (m *Document) filterLines(ctx context.Context) {
+	parent := m.filter.parent
+	n := 0
+	for {
+		endNum := parent.BufEndNum()
+		if endNum < n {
+			m.reset()
+			m.mu.Lock()
+			m.filter.lineNums = m.filter.lineNums[:0]
+			m.mu.Unlock()
+			n = 0
+		}
+
+		for n < endNum {
+			end := min(n+countBlock, endNum)
+			var nums []int
+			var lines []string
+			for ; n < end; n++ {
+				line := parent.GetLine(n)
+				if n < m.filter.header {
+					nums = append(nums, n)
+					lines = append(lines, line)
+					continue
+				}
+				if parent.matchLine(m.filter.searcher, n) == m.filter.invert {
+					continue
+				}
+				if m.filter.occur {
+					line = fmt.Sprintf("%d:%s", n-m.filter.header+1, line)
+				}
+				nums = append(nums, n)
+				lines = append(lines, line)
+			}
+			if len(lines) > 0 {
+				m.appendFilter(nums, lines)
+			}
+			select {
+			case <-ctx.Done():
+				atomic.StoreInt32(&m.eof, 1)
+				return
+			default:
+			}
+		}
+
+		eof := int32(0)
+		if parent.BufEOF() {
+			eof = 1
+		}
+		if atomic.SwapInt32(&m.eof, eof) != eof {
+			atomic.StoreInt32(&m.changed, 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			atomic.StoreInt32(&m.eof, 1)
+			return
+		case <-time.After(filterInterval):
+		}
+	}
+}
+
+// appendFilter appends the lines and the line numbers of the parent document.
+func (m *Document) appendFilter(nums []int, lines []string) {
+	m.mu.Lock()
+	m.filter.lineNums = append(m.filter.lineNums, nums...)
+	m.lines = append(m.lines, lines...)
+	m.endNum += len(lines)
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.changed, 1)
+}
+
+// originLine returns the line number of the parent document of line n.
+func (m *Document) originLine(n int) (int, bool) {
+	if m.filter == nil {
+		return 0, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n < 0 || n >= len(m.filter.lineNums) {
+		return 0, false
+	}
+	return m.filter.lineNums[n], true
+}
+
+// stopFilter stops filtering the parent document.
+func (m *Document) stopFilter() {
+	if m.filter == nil || m.cancel == nil {
+		return
+	}
+	m.cancel()
+}
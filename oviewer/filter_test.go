@@ -0,0 +1,162 @@
+package oviewer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFilter waits until the filtered document has num lines.
+func waitFilter(t *testing.T, m *Document, num int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for m.BufEndNum() < num || !m.BufEOF() {
+		if time.Now().After(deadline) {
+			t.Fatalf("filtered lines = %d, want %d", m.BufEndNum(), num)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDocument_FilterDocument(t *testing.T) {
+	str := "error 1\ninfo 2\nerror 3\ninfo 4\n"
+	tests := []struct {
+		name      string
+		invert    bool
+		wantLines []string
+		wantNums  []int
+	}{
+		{
+			name:      "testMatch",
+			invert:    false,
+			wantLines: []string{"error 1", "error 3"},
+			wantNums:  []int{0, 2},
+		},
+		{
+			name:      "testInvert",
+			invert:    true,
+			wantLines: []string{"info 2", "info 4"},
+			wantNums:  []int{1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ReadReader(strings.NewReader(str)); err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			doc, err := m.FilterDocument(ctx, searchWord{word: "error"}, tt.invert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			waitFilter(t, doc, len(tt.wantLines))
+
+			var lines []string
+			var nums []int
+			for n := 0; n < doc.BufEndNum(); n++ {
+				lines = append(lines, doc.GetLine(n))
+				lN, ok := doc.originLine(n)
+				if !ok {
+					t.Fatalf("Document.originLine(%d) not found", n)
+				}
+				nums = append(nums, lN)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("filtered lines = %v, want %v", lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(nums, tt.wantNums) {
+				t.Errorf("original line numbers = %v, want %v", nums, tt.wantNums)
+			}
+		})
+	}
+}
+
+func TestDocument_FilterDocumentFollow(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadReader(strings.NewReader("error 1\ninfo 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	doc, err := m.FilterDocument(ctx, searchWord{word: "error"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFilter(t, doc, 1)
+
+	// Lines added as in follow mode.
+	m.append("info 3", "error 4")
+	waitFilter(t, doc, 2)
+	if got := doc.GetLine(1); got != "error 4" {
+		t.Errorf("Document.GetLine(1) = %v, want %v", got, "error 4")
+	}
+	if got, _ := doc.originLine(1); got != 3 {
+		t.Errorf("Document.originLine(1) = %v, want %v", got, 3)
+	}
+
+	// The parent document is reset and read again.
+	m.reset()
+	m.append("error 5")
+	deadline := time.Now().Add(5 * time.Second)
+	for doc.GetLine(0) != "error 5" || doc.BufEndNum() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Document.GetLine(0) = %v, want %v", doc.GetLine(0), "error 5")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	doc.stopFilter()
+	if _, ok := m.originLine(0); ok {
+		t.Errorf("Document.originLine() of the parent document is found")
+	}
+}
+
+func TestDocument_OccurDocument(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadReader(strings.NewReader("header\nerror 1\ninfo 2\nerror 3\n")); err != nil {
+		t.Fatal(err)
+	}
+	m.Header = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	doc, err := m.OccurDocument(ctx, searchWord{word: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.isOccur() {
+		t.Errorf("Document.isOccur() = false, want true")
+	}
+	waitFilter(t, doc, 3)
+
+	wantLines := []string{"header", "1:error 1", "3:error 3"}
+	wantNums := []int{0, 1, 3}
+	var lines []string
+	var nums []int
+	for n := 0; n < doc.BufEndNum(); n++ {
+		lines = append(lines, doc.GetLine(n))
+		lN, ok := doc.originLine(n)
+		if !ok {
+			t.Fatalf("Document.originLine(%d) not found", n)
+		}
+		nums = append(nums, lN)
+	}
+	if !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("occur lines = %v, want %v", lines, wantLines)
+	}
+	if !reflect.DeepEqual(nums, wantNums) {
+		t.Errorf("original line numbers = %v, want %v", nums, wantNums)
+	}
+}
@@ -0,0 +1,260 @@
+package oviewer
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Scores of the fuzzy match.
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyScoreBoundary    = 8
+	fuzzyScoreConsecutive = 8
+	fuzzyPenaltyTypo      = 16
+	fuzzyPenaltyGap       = 1
+)
+
+// fuzzyWord is a fuzzy search.
+// The characters of the word appear in the line in order,
+// and some characters of the word may be missing as typos.
+type fuzzyWord struct {
+	word          []rune
+	caseSensitive bool
+}
+
+// newFuzzyWord returns fuzzyWord.
+func newFuzzyWord(word string, caseSensitive bool) fuzzyWord {
+	if !caseSensitive {
+		word = strings.ToLower(word)
+	}
+	return fuzzyWord{
+		word:          []rune(word),
+		caseSensitive: caseSensitive,
+	}
+}
+
+// fuzzyWord Match is a fuzzy search.
+func (substr fuzzyWord) Match(s string) bool {
+	s = stripEscapeSequence(s)
+	_, _, ok := substr.match(s)
+	return ok
+}
+
+// maxTypos returns the number of characters of the word that may be missing.
+func (substr fuzzyWord) maxTypos() int {
+	return len(substr.word) / 4
+}
+
+// match returns the score of the match and the byte positions of the matched characters.
+// The higher score is the better match.
+func (substr fuzzyWord) match(s string) (int, [][]int, bool) {
+	if len(substr.word) == 0 {
+		return 0, nil, false
+	}
+	runes := []rune(s)
+	orig := runes
+	if !substr.caseSensitive {
+		runes = make([]rune, len(orig))
+		for i, r := range orig {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	// Find the characters of the word in order.
+	var matched []rune
+	var positions []int
+	typos := 0
+	j := 0
+	for _, r := range substr.word {
+		k := j
+		for k < len(runes) && runes[k] != r {
+			k++
+		}
+		if k == len(runes) {
+			typos++
+			if typos > substr.maxTypos() {
+				return 0, nil, false
+			}
+			continue
+		}
+		matched = append(matched, r)
+		positions = append(positions, k)
+		j = k + 1
+	}
+	if len(positions) == 0 {
+		return 0, nil, false
+	}
+
+	// Scan backward from the last match to find the shortest range.
+	i := len(matched) - 1
+	for k := positions[i]; k >= 0 && i >= 0; k-- {
+		if runes[k] == matched[i] {
+			positions[i] = k
+			i--
+		}
+	}
+
+	score := -typos * fuzzyPenaltyTypo
+	for n, k := range positions {
+		score += fuzzyScoreMatch
+		if k == 0 || !isWordRune(runes[k-1]) {
+			score += fuzzyScoreBoundary
+		}
+		if n > 0 {
+			if k == positions[n-1]+1 {
+				score += fuzzyScoreConsecutive
+			} else {
+				score -= (k - positions[n-1] - 1) * fuzzyPenaltyGap
+			}
+		}
+	}
+	return score, runePositions(orig, positions), true
+}
+
+// isWordRune returns true if the rune is a letter or a digit.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// runePositions converts the indexes of the runes into the byte ranges.
+func runePositions(runes []rune, indexes []int) [][]int {
+	poss := make([][]int, 0, len(indexes))
+	b, i := 0, 0
+	for k, r := range runes {
+		if i == len(indexes) {
+			break
+		}
+		if k == indexes[i] {
+			poss = append(poss, []int{b, b + utf8.RuneLen(r)})
+			i++
+		}
+		b += utf8.RuneLen(r)
+	}
+	return poss
+}
+
+// fuzzyLine is the line number and the score of the fuzzy match.
+type fuzzyLine struct {
+	lN    int
+	score int
+}
+
+// fuzzyResult is the lines of the fuzzy search in score order.
+type fuzzyResult struct {
+	key   string
+	doc   *Document
+	lines []fuzzyLine
+	// current is the position of the current line in lines.
+	current int
+}
+
+// fuzzyRank returns the matching lines in descending order of the score.
+// The lines with the same score are in the order of the line number.
+func (m *Document) fuzzyRank(ctx context.Context, searcher fuzzyWord) ([]fuzzyLine, error) {
+	start, endNum := m.firstLine(), m.viewEndNum()
+	var ranges [][2]int
+	for n := start; n < endNum; n += searchChunkLines {
+		ranges = append(ranges, [2]int{n, min(n+searchChunkLines, endNum)})
+	}
+
+	// Each worker ranks every searchWorkers-th range.
+	results := make([][]fuzzyLine, len(ranges))
+	eg, ctx := errgroup.WithContext(ctx)
+	for w := 0; w < searchWorkers; w++ {
+		w := w
+		eg.Go(func() error {
+			for i := w; i < len(ranges); i += searchWorkers {
+				for n := ranges[i][0]; n < ranges[i][1]; n++ {
+					if score, _, ok := searcher.match(stripEscapeSequence(m.viewLine(n))); ok {
+						results[i] = append(results[i], fuzzyLine{lN: n, score: score})
+					}
+					select {
+					case <-ctx.Done():
+						return ErrCancel
+					default:
+					}
+				}
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var lines []fuzzyLine
+	for _, r := range results {
+		lines = append(lines, r...)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].score > lines[j].score
+	})
+	return lines, nil
+}
+
+// fuzzyMove moves to the next (or previous) line of the fuzzy search in score order.
+// The lines are ranked when the search is changed.
+func (root *Root) fuzzyMove(ctx context.Context, forward bool, searcher fuzzyWord) {
+	m := root.Doc
+	key := root.searchOptions(root.searchWord) + ":" + root.searchWord
+	if root.fuzzy == nil || root.fuzzy.key != key || root.fuzzy.doc != m {
+		root.setMessagef("fuzzy search:%v (%v)Cancel", root.searchWord, strings.Join(root.cancelKeys, ","))
+		eg, ctx := errgroup.WithContext(ctx)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		root.cancelFunc = cancel
+
+		eg.Go(func() error {
+			return root.cancelWait()
+		})
+		var lines []fuzzyLine
+		eg.Go(func() error {
+			var err error
+			lines, err = m.fuzzyRank(ctx, searcher)
+			root.searchQuit()
+			return err
+		})
+		if err := eg.Wait(); err != nil {
+			root.setMessage(err.Error())
+			return
+		}
+		root.fuzzy = &fuzzyResult{
+			key:     key,
+			doc:     m,
+			lines:   lines,
+			current: -1,
+		}
+	}
+
+	f := root.fuzzy
+	if len(f.lines) == 0 {
+		root.setMessage(ErrNotFound.Error())
+		return
+	}
+	switch {
+	case f.current < 0:
+		f.current = 0
+	case forward:
+		f.current = (f.current + 1) % len(f.lines)
+	default:
+		f.current = (f.current - 1 + len(f.lines)) % len(f.lines)
+	}
+	line := f.lines[f.current]
+	root.moveLine(line.lN - m.firstLine())
+	root.setMessagef("fuzzy:%v rank %d/%d (score %d)", root.searchWord, f.current+1, len(f.lines), line.score)
+}
+
+// fuzzyPosition returns the positions of the matched characters of the fuzzy search.
+func (root *Root) fuzzyPosition(lineStr string) [][]int {
+	searcher := newFuzzyWord(root.searchWord, root.searchCaseSensitive(root.searchWord, root.CaseSensitive))
+	_, poss, ok := searcher.match(lineStr)
+	if !ok {
+		return nil
+	}
+	return poss
+}
@@ -0,0 +1,79 @@
+package oviewer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_fuzzyWord_Match(t *testing.T) {
+	tests := []struct {
+		name          string
+		word          string
+		caseSensitive bool
+		s             string
+		want          bool
+	}{
+		{name: "testSubsequence", word: "cnfail", s: "connection failed", want: true},
+		{name: "testCaseInsensitive", word: "CONN", s: "connection failed", want: true},
+		{name: "testCaseSensitive", word: "CONN", caseSensitive: true, s: "connection failed", want: false},
+		{name: "testTypo", word: "conecxion", s: "connection failed", want: true},
+		{name: "testTooManyTypos", word: "cxxxtion", s: "connection failed", want: false},
+		{name: "testEscapeSequence", word: "err", s: "\x1b[31me\x1b[0mrr", want: true},
+		{name: "testEmpty", word: "", s: "connection failed", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newFuzzyWord(tt.word, tt.caseSensitive).Match(tt.s); got != tt.want {
+				t.Errorf("fuzzyWord.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fuzzyWord_match(t *testing.T) {
+	searcher := newFuzzyWord("fail", false)
+	consecutive, poss, ok := searcher.match("connection failed")
+	if !ok {
+		t.Fatal("fuzzyWord.match() not matched")
+	}
+	if want := [][]int{{11, 12}, {12, 13}, {13, 14}, {14, 15}}; !reflect.DeepEqual(poss, want) {
+		t.Errorf("fuzzyWord.match() positions = %v, want %v", poss, want)
+	}
+	scattered, _, ok := searcher.match("f a i l")
+	if !ok {
+		t.Fatal("fuzzyWord.match() not matched")
+	}
+	if consecutive <= scattered {
+		t.Errorf("fuzzyWord.match() consecutive score %d <= scattered score %d", consecutive, scattered)
+	}
+	// The positions are the byte positions of the multibyte string.
+	_, poss, _ = newFuzzyWord("fa", false).match("あf a")
+	if want := [][]int{{3, 4}, {5, 6}}; !reflect.DeepEqual(poss, want) {
+		t.Errorf("fuzzyWord.match() positions = %v, want %v", poss, want)
+	}
+}
+
+func TestDocument_fuzzyRank(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := "f xx a xx i xx l\nnothing\nfailed\nf a i l\n"
+	if err := m.ReadAll(strings.NewReader(str)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	lines, err := m.fuzzyRank(context.Background(), newFuzzyWord("fail", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, l := range lines {
+		got = append(got, l.lN)
+	}
+	if want := []int{2, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.fuzzyRank() = %v, want %v", got, want)
+	}
+}
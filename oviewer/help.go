@@ -64,6 +64,7 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionNextDoc, "next document")
 	k.writeKeyBind(&b, actionPreviousDoc, "previous document")
 	k.writeKeyBind(&b, actionCloseDoc, "close current document")
+	k.writeKeyBind(&b, actionOpenMember, "open archive member on the top line")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tMark position\n"))
 	fmt.Fprint(&b, "\n")
@@ -77,13 +78,28 @@ func KeyBindString(k KeyBind) string {
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionSearch, "forward search mode")
 	k.writeKeyBind(&b, actionBackSearch, "backward search mode")
+	k.writeKeyBind(&b, actionSearchAll, "search all documents mode")
 	k.writeKeyBind(&b, actionNextSearch, "repeat forward search")
 	k.writeKeyBind(&b, actionNextBackSearch, "repeat backward search")
+	k.writeKeyBind(&b, actionFilter, "filter mode")
+	k.writeKeyBind(&b, actionOriginLine, "go to the original line of the filtered line")
+	k.writeKeyBind(&b, actionOccur, "list the matching lines (Enter goes to the line)")
+	k.writeKeyBind(&b, actionHighlight, "add/remove highlight")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tChange display\n"))
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionWrap, "wrap/nowrap toggle")
+	k.writeKeyBind(&b, actionHexMode, "hex dump toggle")
 	k.writeKeyBind(&b, actionColumnMode, "column mode toggle")
+	k.writeKeyBind(&b, actionAlignMode, "align columns toggle")
+	k.writeKeyBind(&b, actionColumnWidth, "column width mode toggle")
+	k.writeKeyBind(&b, actionHideColumn, "hide the column")
+	k.writeKeyBind(&b, actionMoveColumnL, "move the column to the left")
+	k.writeKeyBind(&b, actionMoveColumnR, "move the column to the right")
+	k.writeKeyBind(&b, actionResetColumns, "show all columns")
+	k.writeKeyBind(&b, actionPinColumns, "pin the columns up to the column toggle")
+	k.writeKeyBind(&b, actionSort, "sort by the column ([string|numeric|size|time] [desc] [uniq])")
+	k.writeKeyBind(&b, actionSearchColumn, "search in the column of the header name")
 	k.writeKeyBind(&b, actionAlternate, "color to alternate rows toggle")
 	k.writeKeyBind(&b, actionLineNumMode, "line number toggle")
 
@@ -116,6 +132,12 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, inputCaseSensitive, "case-sensitive toggle")
 	k.writeKeyBind(&b, inputRegexpSearch, "regular expression search toggle")
 	k.writeKeyBind(&b, inputIncSearch, "incremental search toggle")
+	k.writeKeyBind(&b, inputSmartCase, "smart-case toggle")
+	k.writeKeyBind(&b, inputWholeWord, "whole word search toggle")
+	k.writeKeyBind(&b, inputWildcardSearch, "wildcard search toggle")
+	k.writeKeyBind(&b, inputMultilineSearch, "multi-line search toggle")
+	k.writeKeyBind(&b, inputFuzzySearch, "fuzzy search toggle")
+	k.writeKeyBind(&b, inputColumnSearch, "search in the highlighted column toggle")
 	return b.String()
 }
 
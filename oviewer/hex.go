@@ -0,0 +1,345 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// hexRowBytes is the number of bytes in a row of the hex dump.
+const hexRowBytes = 16
+
+// hexOffsetWidth is the width of the offset column ("00000000: ").
+const hexOffsetWidth = 10
+
+// hexASCIIStart is the start position of the ASCII column.
+const hexASCIIStart = hexOffsetWidth + hexRowBytes*3 + 1
+
+// hexSearchBlock is the size of the block to read when searching for bytes.
+const hexSearchBlock = 1 << 20
+
+// hexDump represents the raw bytes of the document displayed in hex.
+type hexDump struct {
+	// file is opened to read the raw bytes of a file that is not indexed.
+	file *os.File
+	// reader reads the raw bytes on demand.
+	// It is file, or the seekReader that decompresses the file.
+	reader io.ReaderAt
+	// indexed is true if reader is the seekReader of the line index,
+	// and the size is the number of bytes read by the line index.
+	indexed bool
+	// counting is true if the size is counted by decompressing the file in the background.
+	counting bool
+	// size is the number of bytes counted so far.
+	size int64
+	// data is the raw bytes of a document that is not read from file.
+	data []byte
+	// fromLines is true if data is restored from the lines that have been read,
+	// because the document cannot be read again.
+	// The line terminators and the encoding of the original are not restored.
+	fromLines bool
+	// lineNum is the number of lines restored to data.
+	lineNum int
+}
+
+// hexRow returns a row of the hex dump (offset, hex bytes and ASCII) like xxd.
+func hexRow(offset int64, b []byte) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%08x: ", offset)
+	for i := 0; i < hexRowBytes; i++ {
+		if i < len(b) {
+			fmt.Fprintf(&sb, "%02x ", b[i])
+		} else {
+			sb.WriteString("   ")
+		}
+	}
+	sb.WriteByte(' ')
+	for _, c := range b {
+		if c >= 0x20 && c < 0x7f {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+// parseHexPattern returns the bytes of a hex string such as "de ad be ef" or "0xdeadbeef".
+func parseHexPattern(s string) ([]byte, bool) {
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" || len(s)%2 != 0 {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// setHexMode starts or ends the hex dump according to HexMode.
+func (m *Document) setHexMode() {
+	if m.HexMode == m.hexMode() {
+		return
+	}
+
+	var dump *hexDump
+	if m.HexMode {
+		dump = m.newHexDump()
+	}
+
+	m.mu.Lock()
+	if err := m.closeHex(); err != nil {
+		log.Printf("hex: %s", err)
+	}
+	m.hex = dump
+	m.mu.Unlock()
+	m.ClearCache()
+	m.lastContentsNum = -1
+	atomic.StoreInt32(&m.changed, 1)
+}
+
+// hexMode returns true if the document is displayed in hex.
+func (m *Document) hexMode() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hex != nil
+}
+
+// newHexDump returns a hexDump of the document.
+// Compressed files are decompressed on demand.
+// Otherwise, the raw bytes are restored from the lines that have been read.
+func (m *Document) newHexDump() *hexDump {
+	if !m.seekable || m.FileName == "" {
+		return &hexDump{fromLines: true}
+	}
+
+	m.mu.Lock()
+	index := m.index
+	m.mu.Unlock()
+	if m.CFormat != UNCOMPRESSED && index != nil && index.seek != nil {
+		return &hexDump{reader: index.seek, indexed: true}
+	}
+
+	f, err := os.Open(m.FileName)
+	if err != nil {
+		log.Printf("hex: %s", err)
+		return &hexDump{}
+	}
+	if m.CFormat == UNCOMPRESSED {
+		return &hexDump{file: f, reader: f}
+	}
+	h := &hexDump{file: f, reader: newSeekReader(f, m.CFormat), counting: true}
+	go m.countHex(h)
+	return h
+}
+
+// countHex counts the uncompressed bytes of the file that is not indexed.
+// It ends when the hex dump is closed.
+func (m *Document) countHex(h *hexDump) {
+	r := compressedFormatReader(m.CFormat, h.file)
+	buf := make([]byte, hexSearchBlock)
+	for {
+		n, err := r.Read(buf)
+		atomic.AddInt64(&h.size, int64(n))
+		if err != nil {
+			break
+		}
+	}
+	atomic.StoreInt32(&m.changed, 1)
+}
+
+// hexStatus returns the status of the hex dump.
+func (m *Document) hexStatus() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.hex == nil:
+		return ""
+	case m.hex.fromLines:
+		return "(Hex:lines)"
+	}
+	return "(Hex)"
+}
+
+// closeHex ends the hex dump.
+func (m *Document) closeHex() error {
+	if m.hex == nil {
+		return nil
+	}
+	h := m.hex
+	m.hex = nil
+	if h.file == nil {
+		return nil
+	}
+	return h.file.Close()
+}
+
+// hexSize returns the number of raw bytes.
+// The lines read since the last call are restored to the raw bytes.
+// m.mu must be locked.
+func (m *Document) hexSize() int64 {
+	h := m.hex
+	switch {
+	case h.indexed:
+		if m.index == nil {
+			return 0
+		}
+		return m.index.end
+	case h.counting:
+		return atomic.LoadInt64(&h.size)
+	case h.file != nil:
+		fi, err := h.file.Stat()
+		if err != nil {
+			return 0
+		}
+		return fi.Size()
+	}
+	if h.fromLines {
+		for ; h.lineNum < m.endNum; h.lineNum++ {
+			h.data = append(h.data, m.lines[h.lineNum]...)
+			h.data = append(h.data, '\n')
+		}
+	}
+	return int64(len(h.data))
+}
+
+// hexRowNum returns the number of rows of the hex dump.
+// m.mu must be locked.
+func (m *Document) hexRowNum() int {
+	return int((m.hexSize() + hexRowBytes - 1) / hexRowBytes)
+}
+
+// hexRead reads up to size raw bytes from offset.
+func (m *Document) hexRead(offset int64, size int64) []byte {
+	m.mu.Lock()
+	if m.hex == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	end := min64(offset+size, m.hexSize())
+	if offset < 0 || offset >= end {
+		m.mu.Unlock()
+		return nil
+	}
+	reader := m.hex.reader
+	if reader == nil {
+		b := m.hex.data[offset:end]
+		m.mu.Unlock()
+		return b
+	}
+	m.mu.Unlock()
+
+	b := make([]byte, end-offset)
+	n, err := reader.ReadAt(b, offset)
+	if err != nil && err != io.EOF {
+		log.Printf("hexRead: %s", err)
+	}
+	return b[:n]
+}
+
+// hexLine returns row n of the hex dump.
+func (m *Document) hexLine(n int) string {
+	offset := int64(n) * hexRowBytes
+	b := m.hexRead(offset, hexRowBytes)
+	if len(b) == 0 {
+		return ""
+	}
+	return hexRow(offset, b)
+}
+
+// searchBytes searches the raw bytes for the pattern and returns the row that contains the beginning.
+func (m *Document) searchBytes(ctx context.Context, pattern []byte, num int, forward bool) (int, error) {
+	m.mu.Lock()
+	size := m.hexSize()
+	m.mu.Unlock()
+	overlap := int64(len(pattern) - 1)
+
+	if forward {
+		for offset := int64(max(num, 0)) * hexRowBytes; offset < size; offset += hexSearchBlock {
+			select {
+			case <-ctx.Done():
+				return 0, ErrCancel
+			default:
+			}
+			b := m.hexRead(offset, hexSearchBlock+overlap)
+			if i := bytes.Index(b, pattern); i >= 0 {
+				return int((offset + int64(i)) / hexRowBytes), nil
+			}
+		}
+		return 0, ErrNotFound
+	}
+
+	// The match must start before end.
+	end := min64((int64(num)+1)*hexRowBytes, size)
+	for end > 0 {
+		select {
+		case <-ctx.Done():
+			return 0, ErrCancel
+		default:
+		}
+		start := max64(end-hexSearchBlock, 0)
+		b := m.hexRead(start, end-start+overlap)
+		if i := bytes.LastIndex(b, pattern); i >= 0 {
+			return int((start + int64(i)) / hexRowBytes), nil
+		}
+		end = start
+	}
+	return 0, ErrNotFound
+}
+
+// hexSearchPosition returns the positions of the bytes that match the pattern
+// in the hex and ASCII columns of row n.
+func (m *Document) hexSearchPosition(n int, pattern []byte) [][]int {
+	rowStart := int64(n) * hexRowBytes
+	overlap := int64(len(pattern) - 1)
+	start := max64(rowStart-overlap, 0)
+	b := m.hexRead(start, rowStart+hexRowBytes+overlap-start)
+
+	var poss [][]int
+	for i := 0; i+len(pattern) <= len(b); i++ {
+		if !bytes.Equal(b[i:i+len(pattern)], pattern) {
+			continue
+		}
+		for j := start + int64(i); j < start+int64(i+len(pattern)); j++ {
+			col := int(j - rowStart)
+			if col < 0 || col >= hexRowBytes {
+				continue
+			}
+			hx := hexOffsetWidth + col*3
+			poss = append(poss, []int{hx, hx + 2})
+			poss = append(poss, []int{hexASCIIStart + col, hexASCIIStart + col + 1})
+		}
+	}
+	return poss
+}
+
+// hexWord is a search for a byte pattern in hex mode.
+type hexWord struct {
+	pattern []byte
+}
+
+// hexWord Match is whether the hex string of the pattern is included in the hex column.
+// The offset and ASCII columns are not matched.
+// It does not match patterns that span rows.
+func (h hexWord) Match(s string) bool {
+	if len(s) <= hexOffsetWidth {
+		return false
+	}
+	s = s[hexOffsetWidth:min(len(s), hexOffsetWidth+hexRowBytes*3)]
+	var sb strings.Builder
+	for i, c := range h.pattern {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%02x", c)
+	}
+	return strings.Contains(s, sb.String())
+}
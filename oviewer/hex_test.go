@@ -0,0 +1,338 @@
+package oviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_hexRow(t *testing.T) {
+	type args struct {
+		offset int64
+		b      []byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "testFull",
+			args: args{offset: 0, b: []byte("\x7fELF\x02\x01\x01\x00abcdefgh")},
+			want: "00000000: 7f 45 4c 46 02 01 01 00 61 62 63 64 65 66 67 68  .ELF....abcdefgh",
+		},
+		{
+			name: "testShort",
+			args: args{offset: 0x10, b: []byte("ab\n")},
+			want: "00000010: 61 62 0a                                         ab.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hexRow(tt.args.offset, tt.args.b); got != tt.want {
+				t.Errorf("hexRow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseHexPattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		want   []byte
+		wantOK bool
+	}{
+		{
+			name:   "testSpaces",
+			s:      "de ad be ef",
+			want:   []byte{0xde, 0xad, 0xbe, 0xef},
+			wantOK: true,
+		},
+		{
+			name:   "testPrefix",
+			s:      "0x7f454c46",
+			want:   []byte{0x7f, 0x45, 0x4c, 0x46},
+			wantOK: true,
+		},
+		{
+			name:   "testOdd",
+			s:      "abc",
+			want:   nil,
+			wantOK: false,
+		},
+		{
+			name:   "testNotHex",
+			s:      "ELF!",
+			want:   nil,
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseHexPattern(tt.s)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("parseHexPattern() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_hexWord_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern []byte
+		s       string
+		want    bool
+	}{
+		{
+			name:    "testHex",
+			pattern: []byte{0x41, 0x42},
+			s:       hexRow(0, []byte("AB")),
+			want:    true,
+		},
+		{
+			name:    "testOffset",
+			pattern: []byte{0x30},
+			s:       hexRow(0x30, []byte("A")),
+			want:    false,
+		},
+		{
+			name:    "testASCII",
+			pattern: []byte{0xde, 0xad},
+			s:       hexRow(0, []byte("de ad")),
+			want:    false,
+		},
+		{
+			name:    "testShort",
+			pattern: []byte{0x30},
+			s:       "00",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := hexWord{pattern: tt.pattern}
+			if got := h.Match(tt.s); got != tt.want {
+				t.Errorf("hexWord.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func hexTestDocument(t *testing.T, data []byte, file bool) *Document {
+	t.Helper()
+	if file {
+		return hexFileDocument(t, "hex.bin", data)
+	}
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	m.HexMode = true
+	m.setHexMode()
+	return m
+}
+
+func hexFileDocument(t *testing.T, name string, data []byte) *Document {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		m.mu.Lock()
+		m.closeHex()
+		m.mu.Unlock()
+		m.closeIndex()
+	})
+	<-m.eofCh
+	m.HexMode = true
+	m.setHexMode()
+	return m
+}
+
+func TestDocument_hexMode(t *testing.T) {
+	data := []byte("0123456789abcdef\x00\r\n")
+	tests := []struct {
+		name       string
+		file       bool
+		want       []string
+		wantStatus string
+	}{
+		{
+			name:       "testFile",
+			file:       true,
+			wantStatus: "(Hex)",
+			want: []string{
+				"00000000: 30 31 32 33 34 35 36 37 38 39 61 62 63 64 65 66  0123456789abcdef",
+				"00000010: 00 0d 0a                                         ...",
+			},
+		},
+		{
+			name:       "testLines",
+			file:       false,
+			wantStatus: "(Hex:lines)",
+			want: []string{
+				"00000000: 30 31 32 33 34 35 36 37 38 39 61 62 63 64 65 66  0123456789abcdef",
+				"00000010: 00 0a                                            ..",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := hexTestDocument(t, data, tt.file)
+			if got := m.hexStatus(); got != tt.wantStatus {
+				t.Errorf("Document.hexStatus() = %v, want %v", got, tt.wantStatus)
+			}
+			if got := m.viewEndNum(); got != len(tt.want) {
+				t.Fatalf("Document.viewEndNum() = %v, want %v", got, len(tt.want))
+			}
+			for n, want := range tt.want {
+				if got := m.viewLine(n); got != want {
+					t.Errorf("Document.viewLine(%d) = %q, want %q", n, got, want)
+				}
+			}
+			// The lines of the document are not changed.
+			if got := m.BufEndNum(); got != 1 {
+				t.Errorf("Document.BufEndNum() = %v, want %v", got, 1)
+			}
+			if got := m.GetLine(0); got != "0123456789abcdef\x00" {
+				t.Errorf("Document.GetLine(0) = %q, want text", got)
+			}
+			// The rows of the hex dump are searched.
+			if got, err := m.SearchLine(context.Background(), NewSearcher("00000010", nil, false, false), 0); err != nil || got != 1 {
+				t.Errorf("Document.SearchLine() = %v, %v, want %v", got, err, 1)
+			}
+
+			m.HexMode = false
+			m.setHexMode()
+			if got := m.viewLine(0); got != "0123456789abcdef\x00" {
+				t.Errorf("Document.viewLine(0) = %q, want text", got)
+			}
+		})
+	}
+}
+
+func TestDocument_hexModeCompressed(t *testing.T) {
+	data := []byte("0123456789abcdef\x00\r\n")
+	want := []string{
+		"00000000: 30 31 32 33 34 35 36 37 38 39 61 62 63 64 65 66  0123456789abcdef",
+		"00000010: 00 0d 0a                                         ...",
+	}
+	tests := []struct {
+		name     string
+		fileName string
+		compress func(w io.Writer) io.WriteCloser
+	}{
+		{
+			name:     "testGzip",
+			fileName: "hex.gz",
+			compress: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		},
+		{
+			name:     "testZlib",
+			fileName: "hex.zlib",
+			compress: func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := tt.compress(&buf)
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			m := hexFileDocument(t, tt.fileName, buf.Bytes())
+			if got := m.hexStatus(); got != "(Hex)" {
+				t.Errorf("Document.hexStatus() = %v, want %v", got, "(Hex)")
+			}
+			// The size of the file that is not indexed is counted in the background.
+			deadline := time.Now().Add(5 * time.Second)
+			for m.viewEndNum() < len(want) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := m.viewEndNum(); got != len(want) {
+				t.Fatalf("Document.viewEndNum() = %v, want %v", got, len(want))
+			}
+			for n, want := range want {
+				if got := m.viewLine(n); got != want {
+					t.Errorf("Document.viewLine(%d) = %q, want %q", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_searchBytes(t *testing.T) {
+	data := bytes.Repeat([]byte{0}, hexRowBytes*4)
+	// The pattern spans rows 1 and 2.
+	copy(data[hexRowBytes*2-2:], []byte{0xde, 0xad, 0xbe, 0xef})
+	m := hexTestDocument(t, data, true)
+
+	type args struct {
+		num     int
+		forward bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "testForward",
+			args: args{num: 0, forward: true},
+			want: 1,
+		},
+		{
+			name:    "testForwardNotFound",
+			args:    args{num: 2, forward: true},
+			wantErr: true,
+		},
+		{
+			name: "testBackward",
+			args: args{num: 3, forward: false},
+			want: 1,
+		},
+		{
+			name:    "testBackwardNotFound",
+			args:    args{num: 0, forward: false},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.searchBytes(context.Background(), []byte{0xde, 0xad, 0xbe, 0xef}, tt.args.num, tt.args.forward)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Document.searchBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Document.searchBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	want := [][]int{{52, 54}, {73, 74}, {55, 57}, {74, 75}}
+	if got := m.hexSearchPosition(1, []byte{0xde, 0xad, 0xbe, 0xef}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.hexSearchPosition() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package oviewer
+
+import (
+	"regexp"
+)
+
+// HighlightRule represents a pattern that is highlighted with the style
+// independently of the search.
+type HighlightRule struct {
+	// Pattern is a regular expression.
+	// If it is not a valid regular expression, it is a string.
+	Pattern string
+	// Style is the style that applies to the matched range.
+	Style OVStyle
+}
+
+// highlight is a compiled HighlightRule.
+type highlight struct {
+	word  string
+	reg   *regexp.Regexp
+	style OVStyle
+}
+
+// setHighlights compiles the highlight rules of the config.
+// The patterns of the config are case-sensitive.
+func (root *Root) setHighlights() {
+	root.highlights = nil
+	for _, rule := range root.Config.Highlight {
+		root.addHighlight(rule.Pattern, rule.Style, true)
+	}
+}
+
+// addHighlight adds a highlight.
+// Returns false if the pattern is empty or already highlighted.
+func (root *Root) addHighlight(word string, style OVStyle, caseSensitive bool) bool {
+	if word == "" || root.highlightNum(word) >= 0 {
+		return false
+	}
+	reg := regexpCompile(word, caseSensitive)
+	if reg == nil {
+		return false
+	}
+	root.highlights = append(root.highlights, highlight{
+		word:  word,
+		reg:   reg,
+		style: style,
+	})
+	return true
+}
+
+// removeHighlight removes the highlight of the pattern.
+// Returns false if the pattern is not highlighted.
+func (root *Root) removeHighlight(word string) bool {
+	n := root.highlightNum(word)
+	if n < 0 {
+		return false
+	}
+	root.highlights = append(root.highlights[:n], root.highlights[n+1:]...)
+	return true
+}
+
+// highlightNum returns the number of the highlight of the pattern, or -1 if not found.
+func (root *Root) highlightNum(word string) int {
+	for n, h := range root.highlights {
+		if h.word == word {
+			return n
+		}
+	}
+	return -1
+}
+
+// nextHighlightStyle returns the style of the highlight to be added interactively.
+// The styles of StyleHighlights are used in turn.
+func (root *Root) nextHighlightStyle() OVStyle {
+	styles := root.Config.StyleHighlights
+	if len(styles) == 0 {
+		return root.StyleSearchHighlight
+	}
+	return styles[len(root.highlights)%len(styles)]
+}
+
+// toggleHighlight adds the highlight of the input,
+// or removes it if it is already highlighted.
+// The empty input removes all highlights.
+func (root *Root) toggleHighlight(input string) {
+	if input == "" {
+		root.highlights = nil
+		root.setMessage("Remove all highlights")
+		return
+	}
+	if root.removeHighlight(input) {
+		root.setMessagef("Remove highlight %s", input)
+		return
+	}
+	if root.addHighlight(input, root.nextHighlightStyle(), root.CaseSensitive) {
+		root.setMessagef("Highlight %s", input)
+	}
+}
+
+// highlightWords returns the highlighted patterns.
+func (root *Root) highlightWords() []string {
+	words := make([]string, 0, len(root.highlights))
+	for _, h := range root.highlights {
+		words = append(words, h.word)
+	}
+	return words
+}
+
+// multiHighlight applies the styles of the highlights.
+// Apply style to contents.
+func (root *Root) multiHighlight(lc contents, lineStr string, posCV map[int]int) {
+	for _, h := range root.highlights {
+		for _, r := range searchPositionReg(lineStr, h.reg) {
+			RangeStyle(lc, posCV[r[0]], posCV[r[1]], h.style)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_addHighlight(t *testing.T) {
+	root := &Root{Config: NewConfig()}
+	root.Config.Highlight = []HighlightRule{
+		{Pattern: "ERROR", Style: OVStyle{Foreground: "red"}},
+	}
+	root.setHighlights()
+	if got := root.highlightWords(); !reflect.DeepEqual(got, []string{"ERROR"}) {
+		t.Fatalf("Root.highlightWords() = %v, want %v", got, []string{"ERROR"})
+	}
+
+	if !root.addHighlight("request_id=abc", root.nextHighlightStyle(), false) {
+		t.Fatal("Root.addHighlight() = false, want true")
+	}
+	if root.addHighlight("ERROR", root.nextHighlightStyle(), false) {
+		t.Error("Root.addHighlight() of the same pattern = true, want false")
+	}
+	if got := root.highlights[1].style; got != root.Config.StyleHighlights[1] {
+		t.Errorf("style of the added highlight = %v, want %v", got, root.Config.StyleHighlights[1])
+	}
+
+	if !root.removeHighlight("ERROR") {
+		t.Fatal("Root.removeHighlight() = false, want true")
+	}
+	if root.removeHighlight("ERROR") {
+		t.Error("Root.removeHighlight() of the removed pattern = true, want false")
+	}
+	if got := root.highlightWords(); !reflect.DeepEqual(got, []string{"request_id=abc"}) {
+		t.Errorf("Root.highlightWords() = %v, want %v", got, []string{"request_id=abc"})
+	}
+}
+
+func TestRoot_multiHighlight(t *testing.T) {
+	root := &Root{Config: NewConfig()}
+	red := OVStyle{Foreground: "red"}
+	blue := OVStyle{Foreground: "blue"}
+	root.addHighlight("ERROR", red, true)
+	root.addHighlight("id=[0-9]+", blue, true)
+
+	lc := StrToContents("ERROR id=12 error", 8)
+	lineStr, posCV := ContentsToStr(lc)
+	root.multiHighlight(lc, lineStr, posCV)
+
+	redStyle := applyStyle(tcell.StyleDefault, red)
+	blueStyle := applyStyle(tcell.StyleDefault, blue)
+	for x, c := range lc {
+		want := tcell.StyleDefault
+		switch {
+		case x < 5:
+			want = redStyle
+		case x >= 6 && x < 11:
+			want = blueStyle
+		}
+		if c.style != want {
+			t.Errorf("style of %d = %v, want %v", x, c.style, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// HistoryFile is the file to save the input history across sessions.
+// The history is loaded by NewInput and saved when Run exits.
+// The empty string does not save the history.
+var HistoryFile = ""
+
+// HistorySize is the maximum number of entries of each input history.
+var HistorySize = 100
+
+// DefaultHistoryFile returns the history file in the user's state directory
+// ($XDG_STATE_HOME/ov/history.json or ~/.local/state/ov/history.json).
+func DefaultHistoryFile() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "ov", "history.json")
+}
+
+// history is the input histories by name.
+// Each history is in order from the oldest.
+type history map[string][]string
+
+// historyCandidates returns the candidates saved in the history by name.
+func (input *Input) historyCandidates() map[string]*candidate {
+	return map[string]*candidate{
+		"search":            input.SearchCandidate,
+		"goto":              input.GoCandidate,
+		"delimiter":         input.DelimiterCandidate,
+		"section_delimiter": input.SectionDelmCandidate,
+		"view_mode":         input.ModeCandidate,
+		"sort":              input.SortCandidate,
+	}
+}
+
+// readHistory reads the history file.
+// The file that does not exist is an empty history.
+func readHistory(fileName string) (history, error) {
+	hist := make(history)
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return hist, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &hist); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return hist, nil
+}
+
+// mergeHistory adds the entries to the list as the newer entries without duplicates.
+// The list is limited to HistorySize entries.
+func mergeHistory(list []string, entries []string) []string {
+	for _, s := range entries {
+		list = toLast(list, s)
+	}
+	if len(list) > HistorySize {
+		list = list[len(list)-HistorySize:]
+	}
+	return list
+}
+
+// loadHistory adds the history of the file to the candidates.
+func (input *Input) loadHistory(fileName string) error {
+	hist, err := readHistory(fileName)
+	if err != nil {
+		return err
+	}
+	for name, c := range input.historyCandidates() {
+		c.list = mergeHistory(c.list, hist[name])
+	}
+	return nil
+}
+
+// saveHistory merges the candidates into the history of the file and saves it.
+// The history saved by the other processes in the meantime is kept.
+func (input *Input) saveHistory(fileName string) error {
+	hist, err := readHistory(fileName)
+	if err != nil {
+		hist = make(history)
+	}
+	for name, c := range input.historyCandidates() {
+		hist[name] = mergeHistory(hist[name], c.list)
+	}
+	b, err := json.MarshalIndent(hist, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(fileName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	// Replace the file at once so that the history is not broken.
+	f, err := os.CreateTemp(dir, "history")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), fileName)
+}
+
+// saveHistory saves the input history to HistoryFile.
+func (root *Root) saveHistory() {
+	if HistoryFile == "" {
+		return
+	}
+	if err := root.input.saveHistory(HistoryFile); err != nil {
+		log.Printf("save history: %s", err)
+	}
+}
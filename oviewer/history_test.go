@@ -0,0 +1,77 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInput_saveHistory(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "ov", "history.json")
+
+	input := NewInput()
+	input.SearchCandidate.list = []string{"error", "warn", "error"}
+	input.GoCandidate.list = []string{"100"}
+	if err := input.saveHistory(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another session.
+	input2 := NewInput()
+	if err := input2.loadHistory(fileName); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"warn", "error"}; !reflect.DeepEqual(input2.SearchCandidate.list, want) {
+		t.Errorf("search history = %v, want %v", input2.SearchCandidate.list, want)
+	}
+	if want := []string{"100"}; !reflect.DeepEqual(input2.GoCandidate.list, want) {
+		t.Errorf("goto history = %v, want %v", input2.GoCandidate.list, want)
+	}
+	// The default candidates are kept.
+	if want := []string{"│", "\t", "|", ";", ","}; !reflect.DeepEqual(input2.DelimiterCandidate.list, want) {
+		t.Errorf("delimiter history = %v, want %v", input2.DelimiterCandidate.list, want)
+	}
+
+	// The history saved by the first session is kept.
+	input2.SearchCandidate.list = []string{"panic"}
+	if err := input2.saveHistory(fileName); err != nil {
+		t.Fatal(err)
+	}
+	hist, err := readHistory(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"warn", "error", "panic"}; !reflect.DeepEqual(hist["search"], want) {
+		t.Errorf("saved search history = %v, want %v", hist["search"], want)
+	}
+}
+
+func Test_mergeHistory(t *testing.T) {
+	size := HistorySize
+	defer func() {
+		HistorySize = size
+	}()
+	HistorySize = 3
+
+	got := mergeHistory([]string{"a", "b"}, []string{"c", "a", "d", ""})
+	if want := []string{"c", "a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeHistory() = %v, want %v", got, want)
+	}
+}
+
+func Test_readHistory(t *testing.T) {
+	dir := t.TempDir()
+	hist, err := readHistory(filepath.Join(dir, "notfound.json"))
+	if err != nil || len(hist) != 0 {
+		t.Errorf("readHistory() = %v, %v, want empty history", hist, err)
+	}
+
+	fileName := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(fileName, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHistory(fileName); err == nil {
+		t.Errorf("readHistory() error = nil, want error")
+	}
+}
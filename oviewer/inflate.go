@@ -0,0 +1,599 @@
+package oviewer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// inflateWindow is the size of the history that deflate refers to.
+const inflateWindow = 32 << 10
+
+// inflateStep is the number of symbols to decode at one time.
+const inflateStep = 16 << 10
+
+// inflateState is the state of gzipReader.
+type inflateState int
+
+const (
+	stateMemberHeader inflateState = iota
+	stateBlockHeader
+	stateStored
+	stateHuffman
+	stateTrailer
+)
+
+var (
+	lenBase   = [29]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 15, 17, 19, 23, 27, 31, 35, 43, 51, 59, 67, 83, 99, 115, 131, 163, 195, 227, 258}
+	lenExtra  = [29]uint{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0}
+	distBase  = [30]int{1, 2, 3, 4, 5, 7, 9, 13, 17, 25, 33, 49, 65, 97, 129, 193, 257, 385, 513, 769, 1025, 1537, 2049, 3073, 4097, 6145, 8193, 12289, 16385, 24577}
+	distExtra = [30]uint{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+
+	// codeOrder is the order of the code length codes.
+	codeOrder = [19]int{16, 17, 18, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15}
+
+	fixedLit, fixedDist = fixedHuffman()
+)
+
+// huffman is a lookup table of canonical Huffman codes.
+type huffman struct {
+	// table is indexed by the next maxLen bits and holds symbol<<4 | length.
+	table  []uint16
+	maxLen uint
+}
+
+// newHuffman returns a huffman of the code lengths.
+func newHuffman(lens []uint8) (*huffman, error) {
+	var count [16]int
+	maxLen := uint(0)
+	for _, l := range lens {
+		count[l]++
+		if uint(l) > maxLen {
+			maxLen = uint(l)
+		}
+	}
+	count[0] = 0
+
+	var next [16]int
+	code := 0
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+		if code+count[l] > 1<<l {
+			return nil, ErrCorrupt
+		}
+	}
+
+	h := &huffman{
+		table:  make([]uint16, 1<<maxLen),
+		maxLen: maxLen,
+	}
+	for sym, l := range lens {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		// Codes are packed from the most significant bit.
+		rev := 0
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | (c>>i)&1
+		}
+		for i := rev; i < len(h.table); i += 1 << l {
+			h.table[i] = uint16(sym)<<4 | uint16(l)
+		}
+	}
+	return h, nil
+}
+
+// fixedHuffman returns the Huffman codes of the fixed block.
+func fixedHuffman() (*huffman, *huffman) {
+	lit := make([]uint8, 288)
+	for i := range lit {
+		switch {
+		case i < 144:
+			lit[i] = 8
+		case i < 256:
+			lit[i] = 9
+		case i < 280:
+			lit[i] = 7
+		default:
+			lit[i] = 8
+		}
+	}
+	dist := make([]uint8, 32)
+	for i := range dist {
+		dist[i] = 5
+	}
+	l, _ := newHuffman(lit)
+	d, _ := newHuffman(dist)
+	return l, d
+}
+
+// gzipReader is a gzip decompressor that can be restarted
+// at the beginning of a deflate block.
+// It records a checkpoint every span bytes,
+// with the compressed offset, the bit offset and the last window.
+// The first read uses compress/gzip, which is faster,
+// and gzipReader is used to read from the checkpoints.
+type gzipReader struct {
+	r *bufio.Reader
+	// coff is the offset of the next byte to read from r.
+	coff int64
+	// bitBuf holds nbits bits that have been read but not consumed.
+	bitBuf uint64
+	nbits  uint
+
+	// out is the decompressed data following the history.
+	out []byte
+	// pos is the position in out to be returned by Read.
+	pos int
+	// uoff is the uncompressed offset of out[0].
+	uoff int64
+
+	state  inflateState
+	final  bool
+	stored int
+	lit    *huffman
+	dist   *huffman
+
+	// crc and size are verified in the trailer,
+	// except for the member restarted from a checkpoint.
+	crc      uint32
+	size     uint32
+	checkCRC bool
+
+	span         int64
+	last         int64
+	onCheckpoint func(checkpoint)
+	err          error
+}
+
+// newGzipReader returns a gzipReader that reads from the beginning.
+// If onCheckpoint is not nil, it is called every span bytes.
+func newGzipReader(r io.Reader, span int64, onCheckpoint func(checkpoint)) *gzipReader {
+	return &gzipReader{
+		r:            bufio.NewReader(r),
+		state:        stateMemberHeader,
+		checkCRC:     true,
+		span:         span,
+		onCheckpoint: onCheckpoint,
+	}
+}
+
+// resumeGzipReader returns a gzipReader that restarts from the checkpoint.
+// r must be positioned at the compressed offset of the checkpoint.
+// If onCheckpoint is not nil, it is called every span bytes after the checkpoint.
+func resumeGzipReader(r io.Reader, cp checkpoint, span int64, onCheckpoint func(checkpoint)) (*gzipReader, error) {
+	z := &gzipReader{
+		r:            bufio.NewReader(r),
+		coff:         cp.coff,
+		out:          append(make([]byte, 0, len(cp.window)), cp.window...),
+		pos:          len(cp.window),
+		uoff:         cp.uoff - int64(len(cp.window)),
+		state:        stateBlockHeader,
+		span:         span,
+		last:         cp.uoff,
+		onCheckpoint: onCheckpoint,
+	}
+	if cp.bit > 0 {
+		c, err := z.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		z.coff++
+		z.bitBuf = uint64(c >> cp.bit)
+		z.nbits = 8 - cp.bit
+	}
+	return z, nil
+}
+
+// Read reads the decompressed data.
+func (z *gzipReader) Read(p []byte) (int, error) {
+	for z.pos == len(z.out) {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.slide()
+		start := len(z.out)
+		z.err = z.step()
+		if z.checkCRC {
+			z.crc = crc32.Update(z.crc, crc32.IEEETable, z.out[start:])
+			z.size += uint32(len(z.out) - start)
+		}
+	}
+	n := copy(p, z.out[z.pos:])
+	z.pos += n
+	return n, nil
+}
+
+// slide discards the data that has been read, leaving the window.
+func (z *gzipReader) slide() {
+	if len(z.out) < 4*inflateWindow {
+		return
+	}
+	drop := len(z.out) - inflateWindow
+	copy(z.out, z.out[drop:])
+	z.out = z.out[:inflateWindow]
+	z.pos -= drop
+	z.uoff += int64(drop)
+}
+
+// step decodes according to the state.
+func (z *gzipReader) step() error {
+	switch z.state {
+	case stateMemberHeader:
+		return z.readHeader()
+	case stateBlockHeader:
+		z.checkpoint()
+		return z.readBlockHeader()
+	case stateStored:
+		return z.readStored()
+	case stateHuffman:
+		return z.readHuffman()
+	case stateTrailer:
+		return z.readTrailer()
+	}
+	return io.EOF
+}
+
+// checkpoint records a checkpoint at the beginning of the block.
+func (z *gzipReader) checkpoint() {
+	if z.onCheckpoint == nil {
+		return
+	}
+	uoff := z.uoff + int64(len(z.out))
+	if uoff-z.last < z.span {
+		return
+	}
+	bitPos := z.coff*8 - int64(z.nbits)
+	window := z.out[max(0, len(z.out)-inflateWindow):]
+	z.onCheckpoint(checkpoint{
+		uoff:   uoff,
+		coff:   bitPos / 8,
+		bit:    uint(bitPos % 8),
+		window: append(make([]byte, 0, len(window)), window...),
+	})
+	z.last = uoff
+}
+
+// need reads bytes until there are n bits.
+func (z *gzipReader) need(n uint) error {
+	for z.nbits < n {
+		c, err := z.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		z.coff++
+		z.bitBuf |= uint64(c) << z.nbits
+		z.nbits += 8
+	}
+	return nil
+}
+
+// bits returns the next n bits.
+func (z *gzipReader) bits(n uint) (int, error) {
+	if err := z.need(n); err != nil {
+		return 0, err
+	}
+	v := int(z.bitBuf & (1<<n - 1))
+	z.bitBuf >>= n
+	z.nbits -= n
+	return v, nil
+}
+
+// decode returns the next symbol of the Huffman code.
+// The last code of the stream may be shorter than maxLen.
+func (z *gzipReader) decode(h *huffman) (int, error) {
+	if err := z.need(h.maxLen); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, err
+	}
+	e := h.table[z.bitBuf&(1<<h.maxLen-1)]
+	l := uint(e & 15)
+	if l == 0 {
+		return 0, ErrCorrupt
+	}
+	if l > z.nbits {
+		return 0, io.ErrUnexpectedEOF
+	}
+	z.bitBuf >>= l
+	z.nbits -= l
+	return int(e >> 4), nil
+}
+
+// alignByte discards the bits up to the byte boundary.
+func (z *gzipReader) alignByte() {
+	n := z.nbits % 8
+	z.bitBuf >>= n
+	z.nbits -= n
+}
+
+// readHeader reads the header of the gzip member.
+// EOF before the header is the end of the gzip stream.
+func (z *gzipReader) readHeader() error {
+	z.alignByte()
+	if z.nbits == 0 {
+		if _, err := z.r.Peek(1); err != nil {
+			return err
+		}
+	}
+
+	var header [10]byte
+	for i := range header {
+		c, err := z.bits(8)
+		if err != nil {
+			return err
+		}
+		header[i] = byte(c)
+	}
+	if header[0] != 0x1f || header[1] != 0x8b || header[2] != 8 {
+		return gzip.ErrHeader
+	}
+	flg := header[3]
+	if flg&0x04 != 0 {
+		xlen, err := z.bits(16)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < xlen; i++ {
+			if _, err := z.bits(8); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range []byte{0x08, 0x10} {
+		if flg&f == 0 {
+			continue
+		}
+		// Zero-terminated file name or comment.
+		for {
+			c, err := z.bits(8)
+			if err != nil {
+				return err
+			}
+			if c == 0 {
+				break
+			}
+		}
+	}
+	if flg&0x02 != 0 {
+		if _, err := z.bits(16); err != nil {
+			return err
+		}
+	}
+
+	z.crc = 0
+	z.size = 0
+	z.checkCRC = true
+	z.state = stateBlockHeader
+	return nil
+}
+
+// readBlockHeader reads the header of the deflate block.
+func (z *gzipReader) readBlockHeader() error {
+	v, err := z.bits(3)
+	if err != nil {
+		return err
+	}
+	z.final = v&1 == 1
+	switch v >> 1 {
+	case 0:
+		z.alignByte()
+		length, err := z.bits(16)
+		if err != nil {
+			return err
+		}
+		nlength, err := z.bits(16)
+		if err != nil {
+			return err
+		}
+		if length != ^nlength&0xffff {
+			return ErrCorrupt
+		}
+		z.stored = length
+		z.state = stateStored
+	case 1:
+		z.lit, z.dist = fixedLit, fixedDist
+		z.state = stateHuffman
+	case 2:
+		if err := z.readDynamic(); err != nil {
+			return err
+		}
+		z.state = stateHuffman
+	default:
+		return ErrCorrupt
+	}
+	return nil
+}
+
+// readDynamic reads the Huffman codes of the dynamic block.
+func (z *gzipReader) readDynamic() error {
+	hlit, err := z.bits(5)
+	if err != nil {
+		return err
+	}
+	hdist, err := z.bits(5)
+	if err != nil {
+		return err
+	}
+	hclen, err := z.bits(4)
+	if err != nil {
+		return err
+	}
+	nlit, ndist := hlit+257, hdist+1
+
+	var codeLens [19]uint8
+	for i := 0; i < hclen+4; i++ {
+		l, err := z.bits(3)
+		if err != nil {
+			return err
+		}
+		codeLens[codeOrder[i]] = uint8(l)
+	}
+	codes, err := newHuffman(codeLens[:])
+	if err != nil {
+		return err
+	}
+
+	lens := make([]uint8, nlit+ndist)
+	for i := 0; i < len(lens); {
+		sym, err := z.decode(codes)
+		if err != nil {
+			return err
+		}
+		if sym < 16 {
+			lens[i] = uint8(sym)
+			i++
+			continue
+		}
+
+		var rep int
+		var l uint8
+		switch sym {
+		case 16:
+			if i == 0 {
+				return ErrCorrupt
+			}
+			l = lens[i-1]
+			rep, err = z.bits(2)
+			rep += 3
+		case 17:
+			rep, err = z.bits(3)
+			rep += 3
+		default:
+			rep, err = z.bits(7)
+			rep += 11
+		}
+		if err != nil {
+			return err
+		}
+		if i+rep > len(lens) {
+			return ErrCorrupt
+		}
+		for ; rep > 0; rep-- {
+			lens[i] = l
+			i++
+		}
+	}
+
+	if z.lit, err = newHuffman(lens[:nlit]); err != nil {
+		return err
+	}
+	z.dist, err = newHuffman(lens[nlit:])
+	return err
+}
+
+// readStored reads the stored block.
+func (z *gzipReader) readStored() error {
+	n := min(z.stored, inflateStep)
+	// The bytes already read into the bit buffer come first.
+	i := 0
+	for ; i < n && z.nbits >= 8; i++ {
+		z.out = append(z.out, byte(z.bitBuf))
+		z.bitBuf >>= 8
+		z.nbits -= 8
+	}
+	start := len(z.out)
+	z.out = append(z.out, make([]byte, n-i)...)
+	m, err := io.ReadFull(z.r, z.out[start:])
+	z.coff += int64(m)
+	if err != nil {
+		z.out = z.out[:start+m]
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	z.stored -= n
+	if z.stored == 0 {
+		z.endBlock()
+	}
+	return nil
+}
+
+// readHuffman decodes the symbols of the Huffman block.
+func (z *gzipReader) readHuffman() error {
+	for i := 0; i < inflateStep; i++ {
+		sym, err := z.decode(z.lit)
+		if err != nil {
+			return err
+		}
+		switch {
+		case sym < 256:
+			z.out = append(z.out, byte(sym))
+			continue
+		case sym == 256:
+			z.endBlock()
+			return nil
+		}
+
+		sym -= 257
+		if sym >= len(lenBase) {
+			return ErrCorrupt
+		}
+		extra, err := z.bits(lenExtra[sym])
+		if err != nil {
+			return err
+		}
+		length := lenBase[sym] + extra
+
+		dsym, err := z.decode(z.dist)
+		if err != nil {
+			return err
+		}
+		if dsym >= len(distBase) {
+			return ErrCorrupt
+		}
+		extra, err = z.bits(distExtra[dsym])
+		if err != nil {
+			return err
+		}
+		dist := distBase[dsym] + extra
+		if dist > len(z.out) {
+			return ErrCorrupt
+		}
+
+		start := len(z.out) - dist
+		if dist >= length {
+			z.out = append(z.out, z.out[start:start+length]...)
+			continue
+		}
+		// Overlapping copy.
+		for j := 0; j < length; j++ {
+			z.out = append(z.out, z.out[start+j])
+		}
+	}
+	return nil
+}
+
+// endBlock moves to the next block or the trailer.
+func (z *gzipReader) endBlock() {
+	if z.final {
+		z.state = stateTrailer
+		return
+	}
+	z.state = stateBlockHeader
+}
+
+// readTrailer reads and verifies the trailer of the gzip member.
+func (z *gzipReader) readTrailer() error {
+	z.alignByte()
+	crc, err := z.bits(32)
+	if err != nil {
+		return err
+	}
+	size, err := z.bits(32)
+	if err != nil {
+		return err
+	}
+	if z.checkCRC && (uint32(crc) != z.crc || uint32(size) != z.size) {
+		return gzip.ErrChecksum
+	}
+	z.state = stateMemberHeader
+	return nil
+}
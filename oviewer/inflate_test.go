@@ -0,0 +1,157 @@
+package oviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// testSeekData returns text with lines that compresses moderately.
+func testSeekData(size int) []byte {
+	rnd := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	for n := 0; buf.Len() < size; n++ {
+		fmt.Fprintf(&buf, "%08d %x\n", n, rnd.Int63n(1<<(rnd.Intn(60)+1)))
+	}
+	return buf.Bytes()
+}
+
+func testGzip(t testing.TB, data []byte, level int, members int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	size := len(data) / members
+	for i := 0; i < members; i++ {
+		part := data[i*size:]
+		if i < members-1 {
+			part = part[:size]
+		}
+		w, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(part); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func Test_gzipReader(t *testing.T) {
+	random := make([]byte, 200000)
+	rand.New(rand.NewSource(2)).Read(random)
+	tests := []struct {
+		name    string
+		data    []byte
+		level   int
+		members int
+	}{
+		{
+			name:    "testText",
+			data:    testSeekData(3 << 20),
+			level:   gzip.DefaultCompression,
+			members: 1,
+		},
+		{
+			name:    "testRandomStored",
+			data:    random,
+			level:   gzip.NoCompression,
+			members: 1,
+		},
+		{
+			name:    "testHuffmanOnly",
+			data:    testSeekData(300000),
+			level:   gzip.HuffmanOnly,
+			members: 1,
+		},
+		{
+			name:    "testMultiMember",
+			data:    testSeekData(2 << 20),
+			level:   gzip.BestSpeed,
+			members: 3,
+		},
+		{
+			name:    "testEmpty",
+			data:    []byte{},
+			level:   gzip.DefaultCompression,
+			members: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed := testGzip(t, tt.data, tt.level, tt.members)
+			var points []checkpoint
+			got, err := io.ReadAll(newGzipReader(bytes.NewReader(compressed), 1<<18, func(cp checkpoint) {
+				points = append(points, cp)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Fatalf("gzipReader read %d bytes, want %d bytes", len(got), len(tt.data))
+			}
+			if len(tt.data) >= 2<<18 && len(points) == 0 {
+				t.Fatal("gzipReader recorded no checkpoints")
+			}
+
+			for _, cp := range points {
+				z, err := resumeGzipReader(bytes.NewReader(compressed[cp.coff:]), cp, 0, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := io.ReadAll(z)
+				if err != nil {
+					t.Fatalf("resume at %d: %s", cp.uoff, err)
+				}
+				if !bytes.Equal(got, tt.data[cp.uoff:]) {
+					t.Errorf("resume at %d: read %d bytes, want %d bytes", cp.uoff, len(got), len(tt.data)-int(cp.uoff))
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGzipReader compares gzipReader with compress/gzip,
+// which is used for the first read.
+func BenchmarkGzipReader(b *testing.B) {
+	data := testSeekData(4 << 20)
+	for _, level := range []int{gzip.NoCompression, gzip.BestSpeed, gzip.DefaultCompression} {
+		compressed := testGzip(b, data, level, 1)
+		b.Run(fmt.Sprintf("flate/level%d", level), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				r, err := gzip.NewReader(bytes.NewReader(compressed))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := io.Copy(io.Discard, r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("gzipReader/level%d", level), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := io.Copy(io.Discard, newGzipReader(bytes.NewReader(compressed), checkpointSpan, func(checkpoint) {})); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func Test_gzipReaderCorrupt(t *testing.T) {
+	data := testSeekData(10000)
+	compressed := testGzip(t, data, gzip.DefaultCompression, 1)
+	// Break the CRC32 of the trailer.
+	compressed[len(compressed)-8] ^= 0xff
+	if _, err := io.ReadAll(newGzipReader(bytes.NewReader(compressed), 0, nil)); !errors.Is(err, gzip.ErrChecksum) {
+		t.Errorf("gzipReader error = %v, want %v", err, gzip.ErrChecksum)
+	}
+}
@@ -27,6 +27,9 @@ type Input struct {
 	WriteBACandidate      *candidate
 	SectionDelmCandidate  *candidate
 	SectionStartCandidate *candidate
+	HighlightCandidate    *candidate
+	ColumnCandidate       *candidate
+	SortCandidate         *candidate
 }
 
 // InputMode represents the state of the input.
@@ -59,6 +62,18 @@ const (
 	SectionDelimiter
 	// SectionStart is a section start position input mode.
 	SectionStart
+	// Filter is a filter input mode.
+	Filter
+	// Highlight is a highlight input mode.
+	Highlight
+	// SearchAll is a search input mode of all documents.
+	SearchAll
+	// Occur is the search results input mode.
+	Occur
+	// SearchColumn is the input mode of the column to search.
+	SearchColumn
+	// Sort is the input mode of the sort option.
+	Sort
 )
 
 // InputEvent input key events.
@@ -197,6 +212,30 @@ func (root *Root) inputRegexpSearch() {
 	root.Config.RegexpSearch = !root.Config.RegexpSearch
 }
 
+func (root *Root) inputSmartCase() {
+	root.Config.SmartCase = !root.Config.SmartCase
+}
+
+func (root *Root) inputWholeWord() {
+	root.Config.WholeWord = !root.Config.WholeWord
+}
+
+func (root *Root) inputWildcardSearch() {
+	root.Config.WildcardSearch = !root.Config.WildcardSearch
+}
+
+func (root *Root) inputColumnSearch() {
+	root.Config.ColumnSearch = !root.Config.ColumnSearch
+}
+
+func (root *Root) inputFuzzySearch() {
+	root.Config.FuzzySearch = !root.Config.FuzzySearch
+}
+
+func (root *Root) inputMultilineSearch() {
+	root.Config.MultilineSearch = !root.Config.MultilineSearch
+}
+
 // stringWidth returns the number of characters in the input.
 func stringWidth(str string, cursor int) int {
 	width := 0
@@ -248,6 +287,7 @@ func NewInput() *Input {
 			"│",
 			"\t",
 			"|",
+			";",
 			",",
 		},
 	}
@@ -290,7 +330,29 @@ func NewInput() *Input {
 			"0",
 		},
 	}
+	i.SortCandidate = &candidate{
+		list: []string{
+			"string uniq",
+			"time",
+			"size desc",
+			"numeric desc",
+			"numeric",
+			"string",
+		},
+	}
+	i.ColumnCandidate = &candidate{
+		list: []string{},
+	}
+	i.HighlightCandidate = &candidate{
+		list: []string{},
+	}
 	i.EventInput = &normalInput{}
+
+	if HistoryFile != "" {
+		if err := i.loadHistory(HistoryFile); err != nil {
+			log.Printf("load history: %s", err)
+		}
+	}
 	return &i
 }
 
@@ -311,6 +373,15 @@ func (root *Root) setSearchMode() {
 	root.OriginPos = root.Doc.topLN
 }
 
+func (root *Root) setSearchAllMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = SearchAll
+	input.EventInput = newSearchAllInput(input.SearchCandidate)
+	root.OriginPos = root.Doc.topLN
+}
+
 func (root *Root) setBackSearchMode() {
 	input := root.input
 	input.value = ""
@@ -320,6 +391,52 @@ func (root *Root) setBackSearchMode() {
 	root.OriginPos = root.Doc.topLN
 }
 
+func (root *Root) setFilterMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Filter
+	input.EventInput = newFilterInput(input.SearchCandidate)
+}
+
+// setHighlightMode sets the highlight input mode.
+// The candidates are the current highlights, so that they can be selected to remove.
+func (root *Root) setHighlightMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Highlight
+	input.HighlightCandidate.list = root.highlightWords()
+	input.HighlightCandidate.p = 0
+	input.EventInput = newHighlightInput(input.HighlightCandidate)
+}
+
+func (root *Root) setOccurMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Occur
+	input.EventInput = newOccurInput(input.SearchCandidate)
+}
+
+func (root *Root) setSearchColumnMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = SearchColumn
+	input.ColumnCandidate.list = root.Doc.headerNames()
+	input.ColumnCandidate.p = 0
+	input.EventInput = newSearchColumnInput(input.ColumnCandidate)
+}
+
+func (root *Root) setSortMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Sort
+	input.EventInput = newSortInput(input.SortCandidate)
+}
+
 func (root *Root) setDelimiterMode() {
 	input := root.input
 	input.value = ""
@@ -471,6 +588,42 @@ func (s *searchInput) Down(str string) string {
 	return s.clist.down()
 }
 
+// searchAllInput represents the search input mode of all documents.
+type searchAllInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newSearchAllInput returns SearchAllInput.
+func newSearchAllInput(clist *candidate) *searchAllInput {
+	return &searchAllInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *searchAllInput) Prompt() string {
+	return "All/"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *searchAllInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.clist.list = toLast(s.clist.list, str)
+	s.clist.p = 0
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *searchAllInput) Up(str string) string {
+	return s.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *searchAllInput) Down(str string) string {
+	return s.clist.down()
+}
+
 // backSearchInput represents the back search input mode.
 type backSearchInput struct {
 	value string
@@ -507,6 +660,184 @@ func (b *backSearchInput) Down(str string) string {
 	return b.clist.down()
 }
 
+// filterInput represents the filter input mode.
+type filterInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newFilterInput returns FilterInput.
+func newFilterInput(clist *candidate) *filterInput {
+	return &filterInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (f *filterInput) Prompt() string {
+	return "&"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (f *filterInput) Confirm(str string) tcell.Event {
+	f.value = str
+	f.clist.list = toLast(f.clist.list, str)
+	f.clist.p = 0
+	f.SetEventNow()
+	return f
+}
+
+// Up returns strings when the up key is pressed during input.
+func (f *filterInput) Up(str string) string {
+	return f.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (f *filterInput) Down(str string) string {
+	return f.clist.down()
+}
+
+// searchColumnInput represents the input mode of the column to search.
+type searchColumnInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newSearchColumnInput returns SearchColumnInput.
+func newSearchColumnInput(clist *candidate) *searchColumnInput {
+	return &searchColumnInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (c *searchColumnInput) Prompt() string {
+	return "Search column:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (c *searchColumnInput) Confirm(str string) tcell.Event {
+	c.value = str
+	c.clist.list = toLast(c.clist.list, str)
+	c.clist.p = 0
+	c.SetEventNow()
+	return c
+}
+
+// Up returns strings when the up key is pressed during input.
+func (c *searchColumnInput) Up(str string) string {
+	return c.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (c *searchColumnInput) Down(str string) string {
+	return c.clist.down()
+}
+
+// occurInput represents the search results input mode.
+type occurInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newOccurInput returns OccurInput.
+func newOccurInput(clist *candidate) *occurInput {
+	return &occurInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (o *occurInput) Prompt() string {
+	return "Occur:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (o *occurInput) Confirm(str string) tcell.Event {
+	o.value = str
+	o.clist.list = toLast(o.clist.list, str)
+	o.clist.p = 0
+	o.SetEventNow()
+	return o
+}
+
+// Up returns strings when the up key is pressed during input.
+func (o *occurInput) Up(str string) string {
+	return o.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (o *occurInput) Down(str string) string {
+	return o.clist.down()
+}
+
+// sortInput represents the input mode of the sort option.
+type sortInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newSortInput returns SortInput.
+func newSortInput(clist *candidate) *sortInput {
+	return &sortInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *sortInput) Prompt() string {
+	return "Sort:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *sortInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.clist.list = toLast(s.clist.list, str)
+	s.clist.p = 0
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *sortInput) Up(str string) string {
+	return s.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *sortInput) Down(str string) string {
+	return s.clist.down()
+}
+
+// highlightInput represents the highlight input mode.
+type highlightInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newHighlightInput returns HighlightInput.
+func newHighlightInput(clist *candidate) *highlightInput {
+	return &highlightInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (h *highlightInput) Prompt() string {
+	return "Highlight:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (h *highlightInput) Confirm(str string) tcell.Event {
+	h.value = str
+	h.SetEventNow()
+	return h
+}
+
+// Up returns strings when the up key is pressed during input.
+func (h *highlightInput) Up(str string) string {
+	return h.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (h *highlightInput) Down(str string) string {
+	return h.clist.down()
+}
+
 // gotoInput represents the goto input mode.
 type gotoInput struct {
 	value string
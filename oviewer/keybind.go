@@ -51,8 +51,10 @@ const (
 	actionLineNumMode    = "line_number_mode"
 	actionSearch         = "search"
 	actionWrap           = "wrap_mode"
+	actionHexMode        = "hex_mode"
 	actionColumnMode     = "column_mode"
 	actionBackSearch     = "backsearch"
+	actionSearchAll      = "search_all"
 	actionDelimiter      = "delimiter"
 	actionHeader         = "header"
 	actionSkipLines      = "skip_lines"
@@ -63,11 +65,31 @@ const (
 	actionNextDoc        = "next_doc"
 	actionPreviousDoc    = "previous_doc"
 	actionCloseDoc       = "close_doc"
+	actionOpenMember     = "open_member"
+	actionFilter         = "filter"
+	actionOriginLine     = "origin_line"
+	actionHighlight      = "highlight"
+	actionOccur          = "occur"
+	actionSearchColumn   = "search_column"
+	actionAlignMode      = "align_mode"
+	actionColumnWidth    = "column_width"
+	actionHideColumn     = "hide_column"
+	actionMoveColumnL    = "move_column_left"
+	actionMoveColumnR    = "move_column_right"
+	actionResetColumns   = "reset_columns"
+	actionPinColumns     = "pin_columns"
+	actionSort           = "sort"
 	actionToggleMouse    = "toggle_mouse"
 
-	inputCaseSensitive = "input_casesensitive"
-	inputIncSearch     = "input_incsearch"
-	inputRegexpSearch  = "input_regexp_search"
+	inputCaseSensitive   = "input_casesensitive"
+	inputIncSearch       = "input_incsearch"
+	inputRegexpSearch    = "input_regexp_search"
+	inputSmartCase       = "input_smart_case"
+	inputWholeWord       = "input_whole_word"
+	inputWildcardSearch  = "input_wildcard_search"
+	inputMultilineSearch = "input_multiline_search"
+	inputFuzzySearch     = "input_fuzzy_search"
+	inputColumnSearch    = "input_column_search"
 )
 
 func (root *Root) setHandler() map[string]func() {
@@ -108,6 +130,7 @@ func (root *Root) setHandler() map[string]func() {
 		actionMovePrevMark:   root.markPrev,
 		actionViewMode:       root.setViewInputMode,
 		actionWrap:           root.toggleWrapMode,
+		actionHexMode:        root.toggleHexMode,
 		actionColumnMode:     root.toggleColumnMode,
 		actionAlternate:      root.toggleAlternateRows,
 		actionLineNumMode:    root.toggleLineNumMode,
@@ -116,6 +139,7 @@ func (root *Root) setHandler() map[string]func() {
 		actionRemoveAllMark:  root.removeAllMark,
 		actionSearch:         root.setSearchMode,
 		actionBackSearch:     root.setBackSearchMode,
+		actionSearchAll:      root.setSearchAllMode,
 		actionDelimiter:      root.setDelimiterMode,
 		actionHeader:         root.setHeaderMode,
 		actionSkipLines:      root.setSkipLinesMode,
@@ -126,10 +150,30 @@ func (root *Root) setHandler() map[string]func() {
 		actionNextDoc:        root.nextDoc,
 		actionPreviousDoc:    root.previousDoc,
 		actionCloseDoc:       root.closeDocument,
+		actionOpenMember:     root.openMember,
+		actionFilter:         root.setFilterMode,
+		actionOriginLine:     root.originLine,
+		actionHighlight:      root.setHighlightMode,
+		actionOccur:          root.setOccurMode,
+		actionSearchColumn:   root.setSearchColumnMode,
+		actionAlignMode:      root.toggleAlignMode,
+		actionColumnWidth:    root.toggleColumnWidth,
+		actionHideColumn:     root.hideColumn,
+		actionMoveColumnL:    root.moveColumnLeft,
+		actionMoveColumnR:    root.moveColumnRight,
+		actionResetColumns:   root.resetColumns,
+		actionPinColumns:     root.pinColumns,
+		actionSort:           root.setSortMode,
 		actionToggleMouse:    root.toggleMouse,
 		inputCaseSensitive:   root.inputCaseSensitive,
 		inputIncSearch:       root.inputIncSearch,
 		inputRegexpSearch:    root.inputRegexpSearch,
+		inputSmartCase:       root.inputSmartCase,
+		inputWholeWord:       root.inputWholeWord,
+		inputWildcardSearch:  root.inputWildcardSearch,
+		inputMultilineSearch: root.inputMultilineSearch,
+		inputFuzzySearch:     root.inputFuzzySearch,
+		inputColumnSearch:    root.inputColumnSearch,
 	}
 }
 
@@ -174,6 +218,7 @@ func GetKeyBinds(bind map[string][]string) map[string][]string {
 		actionMovePrevMark:   {"<"},
 		actionViewMode:       {"p", "P"},
 		actionWrap:           {"w", "W"},
+		actionHexMode:        {"x"},
 		actionColumnMode:     {"c"},
 		actionAlternate:      {"C"},
 		actionLineNumMode:    {"G"},
@@ -182,6 +227,7 @@ func GetKeyBinds(bind map[string][]string) map[string][]string {
 		actionRemoveMark:     {"M"},
 		actionSearch:         {"/"},
 		actionBackSearch:     {"?"},
+		actionSearchAll:      {"alt+/"},
 		actionDelimiter:      {"d"},
 		actionHeader:         {"H"},
 		actionSkipLines:      {"ctrl+s"},
@@ -192,12 +238,32 @@ func GetKeyBinds(bind map[string][]string) map[string][]string {
 		actionNextDoc:        {"]"},
 		actionPreviousDoc:    {"["},
 		actionCloseDoc:       {"ctrl+k"},
+		actionOpenMember:     {"o"},
+		actionFilter:         {"&"},
+		actionOriginLine:     {"O"},
+		actionHighlight:      {"*"},
+		actionOccur:          {"alt+o"},
+		actionSearchColumn:   {"alt+k"},
+		actionAlignMode:      {"A"},
+		actionColumnWidth:    {"alt+b"},
+		actionHideColumn:     {"alt+h"},
+		actionMoveColumnL:    {"alt+left"},
+		actionMoveColumnR:    {"alt+right"},
+		actionResetColumns:   {"alt+u"},
+		actionPinColumns:     {"alt+p"},
+		actionSort:           {"alt+t"},
 		actionToggleMouse:    {"ctrl+alt+r"},
 		actionSuspend:        {"ctrl+z"},
 
-		inputCaseSensitive: {"alt+c"},
-		inputIncSearch:     {"alt+i"},
-		inputRegexpSearch:  {"alt+r"},
+		inputCaseSensitive:   {"alt+c"},
+		inputIncSearch:       {"alt+i"},
+		inputRegexpSearch:    {"alt+r"},
+		inputSmartCase:       {"alt+s"},
+		inputWholeWord:       {"alt+w"},
+		inputWildcardSearch:  {"alt+g"},
+		inputMultilineSearch: {"alt+m"},
+		inputFuzzySearch:     {"alt+f"},
+		inputColumnSearch:    {"alt+k"},
 	}
 
 	for k, v := range bind {
@@ -259,6 +325,11 @@ func wrapEventHandler(f func()) func(_ *tcell.EventKey) *tcell.EventKey {
 }
 
 func (root *Root) keyCapture(ev *tcell.EventKey) bool {
+	// Enter on the search results moves to the line of the source document.
+	if ev.Key() == tcell.KeyEnter && root.Doc.isOccur() {
+		root.originLine()
+		return true
+	}
 	root.keyConfig.Capture(ev)
 	return true
 }
@@ -0,0 +1,212 @@
+package oviewer
+
+import (
+	"strings"
+)
+
+// layoutEnabled returns true if the columns are hidden or reordered.
+// The columns in the layout are displayed aligned.
+func (m *Document) layoutEnabled() bool {
+	return m.ColumnMode && m.layout != nil && (m.ColumnDelimiter != "" || m.ColumnWidth)
+}
+
+// viewFields returns the fields of the original line in the order of the layout.
+func (m *Document) viewFields(fields []string) []string {
+	if !m.layoutEnabled() {
+		return fields
+	}
+	view := make([]string, 0, len(m.layout))
+	for _, n := range m.layout {
+		if n < len(fields) {
+			view = append(view, fields[n])
+			continue
+		}
+		view = append(view, "")
+	}
+	return view
+}
+
+// originalColumn returns the column number of the original line from the displayed column number.
+func (m *Document) originalColumn(number int) int {
+	if !m.layoutEnabled() || number < 0 || number >= len(m.layout) {
+		return number
+	}
+	return m.layout[number]
+}
+
+// displayColumn returns the displayed column number from the column number of the original line.
+// Returns -1 if the column is hidden.
+func (m *Document) displayColumn(number int) int {
+	if !m.layoutEnabled() {
+		return number
+	}
+	for i, n := range m.layout {
+		if n == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentLayout returns a copy of the layout.
+// It is all the columns of the header if the columns are not hidden or reordered.
+func (m *Document) currentLayout() []int {
+	if m.layout != nil {
+		return append([]int{}, m.layout...)
+	}
+	layout := make([]int, m.columnCount())
+	for i := range layout {
+		layout[i] = i
+	}
+	return layout
+}
+
+// columnCount returns the number of the columns of the header line (the first line after SkipLines).
+func (m *Document) columnCount() int {
+	return len(m.lineFields(stripEscapeSequence(m.GetLine(m.SkipLines))))
+}
+
+// setLayout sets the layout and discards the widths of the aligned columns.
+func (m *Document) setLayout(layout []int) {
+	m.layout = layout
+	m.resetAlign()
+}
+
+// updateLayout sets the layout of ColumnLayout (the header names or the column numbers)
+// when the header is read or ColumnLayout is changed.
+func (m *Document) updateLayout() {
+	key := strings.Join(m.ColumnLayout, ",")
+	if key == m.layoutKey {
+		return
+	}
+	if len(m.ColumnLayout) > 0 && m.BufEndNum() <= m.SkipLines && !m.BufEOF() {
+		return
+	}
+	m.layoutKey = key
+
+	var layout []int
+	for _, name := range m.ColumnLayout {
+		if n, ok := m.headerColumn(name); ok {
+			layout = append(layout, n)
+		}
+	}
+	m.setLayout(layout)
+}
+
+// layoutColumns returns the layout to change.
+// Returns false if it is not in column mode.
+func (root *Root) layoutColumns() ([]int, bool) {
+	m := root.Doc
+	if !m.ColumnMode || (m.ColumnDelimiter == "" && !m.ColumnWidth) {
+		root.setMessage("Set ColumnMode to change the columns")
+		return nil, false
+	}
+	layout := m.currentLayout()
+	if m.columnNum >= len(layout) {
+		root.setMessagef("%s: %d", ErrNoColumn, m.columnNum+1)
+		return nil, false
+	}
+	return layout, true
+}
+
+// hideColumn hides the highlighted column.
+func (root *Root) hideColumn() {
+	layout, ok := root.layoutColumns()
+	if !ok {
+		return
+	}
+	if len(layout) <= 1 {
+		root.setMessage("Cannot hide the last column")
+		return
+	}
+	m := root.Doc
+	n := layout[m.columnNum]
+	m.setLayout(append(layout[:m.columnNum], layout[m.columnNum+1:]...))
+	m.columnNum = min(m.columnNum, len(m.layout)-1)
+	m.x = root.columnModeX()
+	root.setMessagef("Hide column %d", n+1)
+}
+
+// moveColumn moves the highlighted column to the left (negative) or the right.
+func (root *Root) moveColumn(d int) {
+	layout, ok := root.layoutColumns()
+	if !ok {
+		return
+	}
+	m := root.Doc
+	to := m.columnNum + d
+	if to < 0 || to >= len(layout) {
+		return
+	}
+	layout[m.columnNum], layout[to] = layout[to], layout[m.columnNum]
+	m.setLayout(layout)
+	m.columnNum = to
+	m.x = root.columnModeX()
+	root.setMessagef("Move column %d", layout[to]+1)
+}
+
+// moveColumnLeft moves the highlighted column to the left.
+func (root *Root) moveColumnLeft() {
+	root.moveColumn(-1)
+}
+
+// moveColumnRight moves the highlighted column to the right.
+func (root *Root) moveColumnRight() {
+	root.moveColumn(1)
+}
+
+// resetColumns displays all columns in the original order.
+func (root *Root) resetColumns() {
+	m := root.Doc
+	n := m.originalColumn(m.columnNum)
+	m.setLayout(nil)
+	m.columnNum = max(n, 0)
+	m.x = root.columnModeX()
+	root.setMessage("Show all columns")
+}
+
+// pinColumns pins the columns up to the highlighted column on the left.
+// Pinning the same columns again unpins them.
+func (root *Root) pinColumns() {
+	m := root.Doc
+	if !m.ColumnMode {
+		root.setMessage("Set ColumnMode to pin the columns")
+		return
+	}
+	if m.PinColumns == m.columnNum+1 {
+		m.PinColumns = 0
+		root.setMessage("Unpin the columns")
+		return
+	}
+	m.PinColumns = m.columnNum + 1
+	root.setMessagef("Pin %d columns", m.PinColumns)
+}
+
+// pinnedX returns x so that the column starting at sx is not hidden by the pinned columns.
+func (root *Root) pinnedX(lc contents, sx int, x int) int {
+	m := root.Doc
+	if m.columnNum < m.PinColumns {
+		return x
+	}
+	if pinned := root.pinnedWidth(lc); pinned > 0 && sx-x < pinned {
+		return max(sx-pinned, 0)
+	}
+	return x
+}
+
+// pinnedWidth returns the width of the pinned columns and the following separator of the line.
+func (root *Root) pinnedWidth(lc contents) int {
+	m := root.Doc
+	if !m.ColumnMode || m.PinColumns <= 0 || m.WrapMode {
+		return 0
+	}
+	str, posCV := ContentsToStr(lc)
+	if start, _ := m.columnRange(str, m.PinColumns); start > 0 {
+		return posCV[start]
+	}
+	_, end := m.columnRange(str, m.PinColumns-1)
+	if end < 0 {
+		return 0
+	}
+	return posCV[end]
+}
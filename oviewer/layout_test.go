@@ -0,0 +1,123 @@
+package oviewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func layoutTestRoot(t *testing.T, str string) *Root {
+	t.Helper()
+	tcellNewScreen = fakeScreen
+	t.Cleanup(func() {
+		tcellNewScreen = tcell.NewScreen
+	})
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(str)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnMode = true
+	m.ColumnDelimiter = ","
+	return root
+}
+
+func TestRoot_hideColumn(t *testing.T) {
+	root := layoutTestRoot(t, "id,name,age\n1,Alice,30\n")
+	m := root.Doc
+	m.columnNum = 1
+	root.hideColumn()
+	if want := []int{0, 2}; !reflect.DeepEqual(m.layout, want) {
+		t.Fatalf("Root.hideColumn() layout = %v, want %v", m.layout, want)
+	}
+	m.updateAlign(0, 10)
+	if got, _ := m.alignLine("1,Alice,30"); got != "1  │ 30 " {
+		t.Errorf("Document.alignLine() = %q, want %q", got, "1  │ 30 ")
+	}
+
+	root.hideColumn()
+	root.hideColumn()
+	if want := []int{0}; !reflect.DeepEqual(m.layout, want) {
+		t.Errorf("Root.hideColumn() layout = %v, want %v", m.layout, want)
+	}
+
+	root.resetColumns()
+	if m.layout != nil {
+		t.Errorf("Root.resetColumns() layout = %v, want nil", m.layout)
+	}
+}
+
+func TestRoot_moveColumn(t *testing.T) {
+	root := layoutTestRoot(t, "id,name,age\n1,Alice,30\n")
+	m := root.Doc
+	root.moveColumnRight()
+	if want := []int{1, 0, 2}; !reflect.DeepEqual(m.layout, want) {
+		t.Fatalf("Root.moveColumnRight() layout = %v, want %v", m.layout, want)
+	}
+	if m.columnNum != 1 {
+		t.Errorf("Root.moveColumnRight() columnNum = %d, want 1", m.columnNum)
+	}
+	root.moveColumnLeft()
+	root.moveColumnLeft()
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(m.layout, want) {
+		t.Errorf("Root.moveColumnLeft() layout = %v, want %v", m.layout, want)
+	}
+
+	// The search is restricted to the column of the original line.
+	m.setLayout([]int{2, 0})
+	m.columnNum = 0
+	root.Config.ColumnSearch = true
+	searcher := root.columnSearcher(NewSearcher("30", nil, false, false))
+	if !searcher.Match("1,Alice,30") {
+		t.Errorf("columnWord.Match() = false, want true")
+	}
+}
+
+func TestDocument_updateLayout(t *testing.T) {
+	root := layoutTestRoot(t, "id,name,age\n1,Alice,30\n")
+	m := root.Doc
+	m.ColumnLayout = []string{"age", "1", "unknown"}
+	m.updateLayout()
+	if want := []int{2, 0}; !reflect.DeepEqual(m.layout, want) {
+		t.Fatalf("Document.updateLayout() layout = %v, want %v", m.layout, want)
+	}
+	if got := m.displayColumn(1); got != -1 {
+		t.Errorf("Document.displayColumn() = %d, want -1", got)
+	}
+	if got := m.originalColumn(0); got != 2 {
+		t.Errorf("Document.originalColumn() = %d, want 2", got)
+	}
+
+	// The layout changed by the actions is kept.
+	m.setLayout([]int{1})
+	m.updateLayout()
+	if want := []int{1}; !reflect.DeepEqual(m.layout, want) {
+		t.Errorf("Document.updateLayout() layout = %v, want %v", m.layout, want)
+	}
+}
+
+func TestRoot_pinnedWidth(t *testing.T) {
+	root := layoutTestRoot(t, "id,name,age\n1,Alice,30\n")
+	m := root.Doc
+	lc := parseString("1,Alice,30", 8)
+	if got := root.pinnedWidth(lc); got != 0 {
+		t.Errorf("Root.pinnedWidth() = %d, want 0", got)
+	}
+	m.PinColumns = 2
+	if got := root.pinnedWidth(lc); got != 8 {
+		t.Errorf("Root.pinnedWidth() = %d, want 8", got)
+	}
+	m.PinColumns = 3
+	if got := root.pinnedWidth(lc); got != 10 {
+		t.Errorf("Root.pinnedWidth() = %d, want 10", got)
+	}
+}
@@ -0,0 +1,198 @@
+package oviewer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// chunkLines is the number of lines in one chunk of the line index.
+const chunkLines = 4096
+
+// chunkCacheSize is the maximum number of bytes of chunks to be cached.
+const chunkCacheSize = 64 << 20
+
+// lineChunk represents the position of chunkLines lines in the file.
+type lineChunk struct {
+	// start is the offset of the first line of the chunk.
+	start int64
+	// lens is the byte length of each line including the line terminator.
+	lens []uint32
+}
+
+// lineIndex is a line-offset index of a seekable file.
+// lineIndex does not hold the contents of the lines,
+// but reads them from the file when needed.
+// The read chunks are kept in a bounded cache.
+// The offsets of a compressed file are offsets in the uncompressed data.
+type lineIndex struct {
+	// file is opened for reading only the lines.
+	file *os.File
+	// reader reads the lines from file.
+	reader io.ReaderAt
+	// seek is the reader of the compressed file, or nil if not compressed.
+	seek *seekReader
+	// chunks is the list of chunks.
+	chunks []*lineChunk
+	// end is the offset following the last line.
+	end int64
+	// cache is a cache of chunks read from the file.
+	cache *ristretto.Cache
+	// sealed is true if no more lines are added,
+	// because the file has been rotated.
+	sealed bool
+}
+
+// newLineIndex returns a lineIndex of the file.
+// The compressed file is read by seekReader.
+func newLineIndex(fileName string, cFormat Compressed) (*lineIndex, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 10000,          // number of keys to track frequency of.
+		MaxCost:     chunkCacheSize, // maximum cost of cache (bytes).
+		BufferItems: 64,             // number of keys per Get buffer.
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	idx := &lineIndex{
+		file:   f,
+		reader: f,
+		cache:  cache,
+	}
+	if cFormat != UNCOMPRESSED {
+		idx.seek = newSeekReader(f, cFormat)
+		idx.reader = idx.seek
+	}
+	return idx, nil
+}
+
+// add adds a line of size bytes following the last line.
+func (idx *lineIndex) add(size int) {
+	n := len(idx.chunks)
+	if n == 0 || len(idx.chunks[n-1].lens) >= chunkLines {
+		idx.chunks = append(idx.chunks, &lineChunk{
+			start: idx.end,
+			lens:  make([]uint32, 0, chunkLines),
+		})
+		n++
+	}
+	chunk := idx.chunks[n-1]
+	chunk.lens = append(chunk.lens, uint32(size))
+	idx.end += int64(size)
+}
+
+// lineNum returns the number of lines in the index.
+func (idx *lineIndex) lineNum() int {
+	n := len(idx.chunks)
+	if n == 0 {
+		return 0
+	}
+	return (n-1)*chunkLines + len(idx.chunks[n-1].lens)
+}
+
+// chunk returns a copy of the chunk containing line n.
+// The copy is not affected by the subsequent add.
+func (idx *lineIndex) chunk(n int) (int, lineChunk) {
+	cn := n / chunkLines
+	return cn, *idx.chunks[cn]
+}
+
+// line returns line n from the chunk.
+// The chunk is read from the file if it is not cached.
+func (idx *lineIndex) line(cn int, chunk lineChunk, n int) (string, error) {
+	// The cache holds only the chunks, so the key is the chunk number.
+	key := uint64(cn)
+	if value, found := idx.cache.Get(key); found {
+		// The cached chunk may have fewer lines than the current chunk.
+		if lines, ok := value.([]string); ok && len(lines) > n%chunkLines {
+			return lines[n%chunkLines], nil
+		}
+	}
+
+	lines, size, err := idx.readChunk(chunk)
+	if err != nil {
+		return "", err
+	}
+	idx.cache.Set(key, lines, size)
+	return lines[n%chunkLines], nil
+}
+
+// readChunk reads the chunk from the file and splits it into lines.
+func (idx *lineIndex) readChunk(chunk lineChunk) ([]string, int64, error) {
+	var size int64
+	for _, l := range chunk.lens {
+		size += int64(l)
+	}
+	buf := make([]byte, size)
+	if _, err := idx.reader.ReadAt(buf, chunk.start); err != nil && err != io.EOF {
+		return nil, 0, fmt.Errorf("read chunk: %w", err)
+	}
+
+	str := string(buf)
+	lines := make([]string, len(chunk.lens))
+	pos := 0
+	for i, l := range chunk.lens {
+		lines[i] = trimLineEnd(str[pos : pos+int(l)])
+		pos += int(l)
+	}
+	return lines, size, nil
+}
+
+// lineAt returns the line number of the line starting at offset.
+func (idx *lineIndex) lineAt(offset int64) (int, bool) {
+	cn := sort.Search(len(idx.chunks), func(i int) bool {
+		return idx.chunks[i].start > offset
+	}) - 1
+	if cn < 0 {
+		return 0, false
+	}
+
+	pos := idx.chunks[cn].start
+	for i, l := range idx.chunks[cn].lens {
+		if pos == offset {
+			return cn*chunkLines + i, true
+		}
+		if pos > offset {
+			break
+		}
+		pos += int64(l)
+	}
+	return 0, false
+}
+
+// reset clears the index.
+func (idx *lineIndex) reset() {
+	idx.chunks = nil
+	idx.end = 0
+	idx.sealed = false
+	idx.cache.Clear()
+	if idx.seek != nil {
+		idx.seek.reset()
+	}
+}
+
+// close closes the file of the index.
+func (idx *lineIndex) close() error {
+	idx.cache.Close()
+	return idx.file.Close()
+}
+
+// trimLineEnd removes the line terminator ("\n" or "\r\n").
+func trimLineEnd(line string) string {
+	n := len(line)
+	if n > 0 && line[n-1] == '\n' {
+		n--
+		if n > 0 && line[n-1] == '\r' {
+			n--
+		}
+	}
+	return line[:n]
+}
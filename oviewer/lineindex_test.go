@@ -0,0 +1,110 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_trimLineEnd(t *testing.T) {
+	type args struct {
+		line string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "testLF",
+			args: args{line: "abc\n"},
+			want: "abc",
+		},
+		{
+			name: "testCRLF",
+			args: args{line: "abc\r\n"},
+			want: "abc",
+		},
+		{
+			name: "testNoEOL",
+			args: args{line: "abc"},
+			want: "abc",
+		},
+		{
+			name: "testCR",
+			args: args{line: "abc\r"},
+			want: "abc\r",
+		},
+		{
+			name: "testEmpty",
+			args: args{line: "\n"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimLineEnd(tt.args.line); got != tt.want {
+				t.Errorf("trimLineEnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_lineIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want []string
+	}{
+		{
+			name: "testLF",
+			str:  "a\nbb\n\nccc\n",
+			want: []string{"a", "bb", "", "ccc"},
+		},
+		{
+			name: "testCRLF",
+			str:  "a\r\nbb\r\n",
+			want: []string{"a", "bb"},
+		},
+		{
+			name: "testNoEOL",
+			str:  "a\nbb",
+			want: []string{"a", "bb"},
+		},
+		{
+			name: "testChunks",
+			str:  strings.Repeat("line\n", chunkLines*2+1),
+			want: strings.Split(strings.Repeat("line\n", chunkLines*2+1), "\n")[:chunkLines*2+1],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "index.txt")
+			if err := os.WriteFile(fileName, []byte(tt.str), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m, err := OpenDocument(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if m.index == nil {
+				t.Fatal("line index is not used")
+			}
+			defer m.closeIndex()
+
+			if got := m.BufEndNum(); got != len(tt.want) {
+				t.Fatalf("Document.BufEndNum() = %v, want %v", got, len(tt.want))
+			}
+			for n, want := range tt.want {
+				if got := m.GetLine(n); got != want {
+					t.Errorf("Document.GetLine(%d) = %v, want %v", n, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// countInterval is the interval to count the matches of the lines added to the document.
+var countInterval = 100 * time.Millisecond
+
+// countBlock is the number of lines to count before updating the number of matches.
+const countBlock = 10000
+
+// searchMatches represents the lines that match the search counted in the background.
+type searchMatches struct {
+	// key identifies the search.
+	key string
+	// lines is the matching line numbers in ascending order.
+	lines []int
+	// scanned is the number of lines counted.
+	scanned int
+	// stopped is true if counting has stopped at EOF of the document that is not followed.
+	stopped bool
+	// cancel cancels counting.
+	cancel context.CancelFunc
+}
+
+// startCountMatch starts counting the lines that match searcher in the background.
+// The count of a different search is canceled.
+// The lines added (as in follow mode) are counted until stopCountMatch is called
+// or the document that is not followed reaches EOF.
+// The count that has stopped at EOF is resumed from the lines counted.
+func (m *Document) startCountMatch(key string, searcher Searcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.matches != nil {
+		if m.matches.key == key {
+			if m.matches.stopped {
+				ctx, cancel := context.WithCancel(context.Background())
+				m.matches.stopped = false
+				m.matches.cancel = cancel
+				go m.countMatches(ctx, m.matches, searcher, m.matches.scanned)
+			}
+			return
+		}
+		m.matches.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	matches := &searchMatches{
+		key:    key,
+		cancel: cancel,
+	}
+	m.matches = matches
+	go m.countMatches(ctx, matches, searcher, 0)
+}
+
+// stopCountMatch stops counting.
+func (m *Document) stopCountMatch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.matches == nil {
+		return
+	}
+	m.matches.cancel()
+	m.matches = nil
+}
+
+// countMatches counts the matching lines from line n.
+// If the document is reset (reload or truncated), it is counted again from the beginning.
+func (m *Document) countMatches(ctx context.Context, matches *searchMatches, searcher Searcher, n int) {
+	for {
+		endNum := m.BufEndNum()
+		if endNum < n {
+			m.mu.Lock()
+			matches.lines = matches.lines[:0]
+			matches.scanned = 0
+			m.mu.Unlock()
+			n = 0
+		}
+
+		for n < endNum {
+			end := min(n+countBlock, endNum)
+			var lines []int
+			for ; n < end; n++ {
+				if m.matchLine(searcher, n) {
+					lines = append(lines, n)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			m.mu.Lock()
+			matches.lines = append(matches.lines, lines...)
+			matches.scanned = n
+			m.mu.Unlock()
+			atomic.StoreInt32(&m.changed, 1)
+		}
+
+		// No lines are added to the document that is not followed after EOF.
+		if m.BufEOF() && atomic.LoadInt32(&m.openFollow) == 0 {
+			m.mu.Lock()
+			if n >= m.endNum {
+				matches.stopped = true
+				m.mu.Unlock()
+				return
+			}
+			m.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(countInterval):
+		}
+	}
+}
+
+// matchStatus returns the number of matches up to line lN and the total number of matches.
+// done is false while counting.
+func (m *Document) matchStatus(lN int) (int, int, bool, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.matches == nil {
+		return 0, 0, false, false
+	}
+	i := sort.SearchInts(m.matches.lines, lN+1)
+	return i, len(m.matches.lines), m.matches.scanned >= m.endNum, true
+}
+
+// countMatch starts counting the matches of the search in the current document.
+func (root *Root) countMatch() {
+	m := root.Doc
+	if m == nil {
+		return
+	}
+	// The rows of the hex dump are not the matches.
+	if root.searchWord == "" || m.hexMode() {
+		m.stopCountMatch()
+		return
+	}
+	key := root.searchOptions(root.searchWord) + ":" + root.searchWord
+	m.startCountMatch(key, root.searcher(root.CaseSensitive))
+}
+
+// matchStatus returns the status string of the matches, such as "match 3/30".
+func (root *Root) matchStatus() string {
+	m := root.Doc
+	// The matches are counted in the lines of the document, not the lines of the tail.
+	if m.viewOverlaid() {
+		return ""
+	}
+	i, total, done, ok := m.matchStatus(m.topLN + m.firstLine())
+	if !ok {
+		return ""
+	}
+	more := ""
+	if !done {
+		more = "+"
+	}
+	return fmt.Sprintf("match %d/%d%s ", i, total, more)
+}
@@ -0,0 +1,128 @@
+package oviewer
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// waitMatch waits until counting is done and returns the number of matches up to lN and the total.
+func waitMatch(t *testing.T, m *Document, lN int, total int) int {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		i, got, done, ok := m.matchStatus(lN)
+		if ok && done && got == total {
+			return i
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Document.matchStatus() = %v, %v, %v, want total %v", got, done, ok, total)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// countStopped waits until counting stops and returns true if it has stopped.
+func countStopped(t *testing.T, m *Document) bool {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		stopped := m.matches != nil && m.matches.stopped
+		m.mu.Unlock()
+		if stopped {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestDocument_startCountMatch(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader("test1\na\ntest2\nb\ntest3\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	defer m.stopCountMatch()
+
+	m.startCountMatch("test", searchWord{word: "test"})
+	if got := waitMatch(t, m, 2, 3); got != 2 {
+		t.Errorf("Document.matchStatus(2) = %v, want %v", got, 2)
+	}
+	if got := waitMatch(t, m, 1, 3); got != 1 {
+		t.Errorf("Document.matchStatus(1) = %v, want %v", got, 1)
+	}
+
+	// Counting stops at EOF of the document that is not followed.
+	if !countStopped(t, m) {
+		t.Fatal("Document.countMatches() does not stop at EOF")
+	}
+
+	// Lines added in follow mode are counted after resuming.
+	atomic.StoreInt32(&m.openFollow, 1)
+	m.startCountMatch("test", searchWord{word: "test"})
+	m.append("test4")
+	if got := waitMatch(t, m, 5, 4); got != 4 {
+		t.Errorf("Document.matchStatus(5) = %v, want %v", got, 4)
+	}
+	m.append("test5")
+	if got := waitMatch(t, m, 6, 5); got != 5 {
+		t.Errorf("Document.matchStatus(6) = %v, want %v", got, 5)
+	}
+
+	// A different search is counted again.
+	m.startCountMatch("a", searchWord{word: "a"})
+	if got := waitMatch(t, m, 5, 1); got != 1 {
+		t.Errorf("Document.matchStatus(5) = %v, want %v", got, 1)
+	}
+
+	m.stopCountMatch()
+	if _, _, _, ok := m.matchStatus(0); ok {
+		t.Error("Document.matchStatus() after stop is ok")
+	}
+}
+
+func TestRoot_countMatchStop(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	docs := make([]*Document, 2)
+	for i := range docs {
+		m, err := NewDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ReadAll(strings.NewReader("test1\na\ntest2\n")); err != nil {
+			t.Fatal(err)
+		}
+		<-m.eofCh
+		docs[i] = m
+	}
+	root, err := NewOviewer(docs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.setSearcher("test", false)
+	waitMatch(t, docs[0], 2, 2)
+
+	// The count of the hidden document is canceled.
+	root.switchDocument(1)
+	if _, _, _, ok := docs[0].matchStatus(0); ok {
+		t.Error("Document.matchStatus() of the hidden document is ok")
+	}
+	waitMatch(t, docs[1], 2, 2)
+
+	// The count is canceled when the search word is cleared.
+	root.setSearcher("", false)
+	if _, _, _, ok := docs[1].matchStatus(0); ok {
+		t.Error("Document.matchStatus() after clearing the search is ok")
+	}
+}
@@ -12,6 +12,7 @@ import (
 func (root *Root) moveTop() {
 	root.resetSelect()
 	defer root.releaseEventBuffer()
+	root.Doc.dropTail()
 	root.moveLine(0)
 }
 
@@ -20,7 +21,8 @@ func (root *Root) moveTop() {
 func (root *Root) moveBottom() {
 	root.resetSelect()
 	defer root.releaseEventBuffer()
-	tx, tn := root.bottomLineNum(root.Doc.BufEndNum())
+	root.Doc.openTail()
+	tx, tn := root.bottomLineNum(root.Doc.viewEndNum())
 	root.Doc.topLN = tn
 	root.Doc.topLX = tx
 }
@@ -28,7 +30,7 @@ func (root *Root) moveBottom() {
 // Move to the specified line.
 func (root *Root) moveLine(lN int) int {
 	lN = max(lN, 0)
-	lN = min(lN, root.Doc.BufEndNum())
+	lN = min(lN, root.Doc.viewEndNum())
 	root.Doc.topLN = lN
 	root.Doc.topLX = 0
 	return lN
@@ -76,8 +78,8 @@ func (root *Root) movePgDn() {
 
 func (root *Root) limitMoveDown(x int, y int) {
 	m := root.Doc
-	if y+root.vHight >= root.Doc.BufEndNum()-root.Doc.SkipLines {
-		tx, tn := root.bottomLineNum(root.Doc.BufEndNum())
+	if y+root.vHight >= root.Doc.viewEndNum()-root.Doc.SkipLines {
+		tx, tn := root.bottomLineNum(root.Doc.viewEndNum())
 		if y > tn || (y == tn && x > tx) {
 			if m.topLN < tn || (m.topLN == tn && m.topLX < tx) {
 				m.topLN = tn
@@ -170,7 +172,7 @@ func (root *Root) moveNumDown(moveY int) {
 	for y := 0; y < moveY; y++ {
 		if n >= len(listX) {
 			num++
-			if num > m.BufEndNum() {
+			if num > m.viewEndNum() {
 				break
 			}
 			listX, err = root.leftMostX(num)
@@ -327,7 +329,7 @@ func (root *Root) lastSection() {
 
 	m := root.Doc
 	// +1 to avoid if the bottom line is a session delimiter.
-	num := m.BufEndNum() - 2
+	num := m.viewEndNum() - 2
 	searcher := NewSearcher(root.Doc.SectionDelimiter, root.Doc.SectionDelimiterReg, true, true)
 	ctx := context.Background()
 	defer ctx.Done()
@@ -384,6 +386,15 @@ func (root *Root) moveRight() {
 // columnModeX returns the actual x from m.columnNum.
 func (root *Root) columnModeX() int {
 	m := root.Doc
+	// The widths of the aligned columns may have been discarded.
+	m.updateAlign(m.topLN, root.vHight)
+	if m.ColumnWidth {
+		num := len(m.columnBounds)
+		if m.layoutEnabled() {
+			num = len(m.layout)
+		}
+		m.columnNum = max(0, min(m.columnNum, num-1))
+	}
 	// m.firstLine()+10 = Maximum columnMode target.
 	for i := 0; i < m.firstLine()+10; i++ {
 		lc, err := m.contentsLN(m.topLN+m.firstLine()+i, m.TabWidth)
@@ -392,14 +403,22 @@ func (root *Root) columnModeX() int {
 		}
 		lineStr, posCV := ContentsToStr(lc)
 		// Skip lines that do not contain a delimiter.
-		if !strings.Contains(lineStr, m.ColumnDelimiter) {
+		delimiter := m.ColumnDelimiter
+		if m.alignEnabled() {
+			delimiter = alignSeparator
+		}
+		if !m.ColumnWidth && !strings.Contains(lineStr, delimiter) {
+			continue
+		}
+		// Skip lines that are shorter than the column.
+		if start, _ := m.columnRange(lineStr, m.columnNum); m.ColumnWidth && start < 0 {
 			continue
 		}
 
-		start, end := rangePosition(lineStr, m.ColumnDelimiter, m.columnNum)
+		start, end := m.columnRange(lineStr, m.columnNum)
 		if start < 0 || end < 0 || (start == len(lineStr)) {
 			m.columnNum--
-			start, end = rangePosition(lineStr, m.ColumnDelimiter, m.columnNum)
+			start, end = m.columnRange(lineStr, m.columnNum)
 		}
 		sx := posCV[start]
 		ex := posCV[end] + 10
@@ -407,7 +426,7 @@ func (root *Root) columnModeX() int {
 			return 0
 		}
 		if ex-root.vWidth > 0 {
-			return ex - root.vWidth
+			return root.pinnedX(lc, sx, ex-root.vWidth)
 		}
 		return sx
 	}
@@ -0,0 +1,114 @@
+package oviewer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultMultilineWindow is the number of lines that a match can span if MultilineWindow is not specified.
+const defaultMultilineWindow = 10
+
+// multilineWord is a regular expression search that matches across the lines.
+// The lines are joined with "\n", and a match starts in a line and ends within the window lines.
+type multilineWord struct {
+	word   *regexp.Regexp
+	window int
+}
+
+// multilineWord Match matches a line.
+func (substr multilineWord) Match(s string) bool {
+	s = stripEscapeSequence(s)
+	return substr.word.MatchString(s)
+}
+
+// matchLines returns true if a match starts in line n.
+func (substr multilineWord) matchLines(m *Document, n int) bool {
+	lines := make([]string, 0, substr.window)
+	for i := n; i < n+substr.window && i < m.BufEndNum(); i++ {
+		lines = append(lines, stripEscapeSequence(m.GetLine(i)))
+	}
+	if len(lines) == 0 {
+		return false
+	}
+	loc := substr.word.FindStringIndex(strings.Join(lines, "\n"))
+	// The newline at the end of the first line belongs to the first line.
+	return loc != nil && loc[0] <= len(lines[0])
+}
+
+// matchLine returns true if line n matches searcher.
+// The multi-line search matches the first line of the match.
+func (m *Document) matchLine(searcher Searcher, n int) bool {
+	if w, ok := searcher.(multilineWord); ok {
+		return w.matchLines(m, n)
+	}
+	return searcher.Match(m.GetLine(n))
+}
+
+// multilineWindow returns the number of lines that a match can span.
+func (root *Root) multilineWindow() int {
+	if root.Config.MultilineWindow <= 0 {
+		return defaultMultilineWindow
+	}
+	return root.Config.MultilineWindow
+}
+
+// multilinePosition returns the positions in line lN of the matches across the lines.
+// The matches that start in the previous lines within the window are also contained.
+func (root *Root) multilinePosition(lN int) [][]int {
+	m := root.Doc
+	if root.searchReg == nil {
+		return nil
+	}
+	window := root.multilineWindow()
+	strs := make(map[int]string)
+	lineStr := func(n int) (string, bool) {
+		if s, ok := strs[n]; ok {
+			return s, true
+		}
+		lc, err := m.contentsLN(n, m.TabWidth)
+		if err != nil {
+			return "", false
+		}
+		s, _ := ContentsToStr(lc)
+		strs[n] = s
+		return s, true
+	}
+
+	line, ok := lineStr(lN)
+	if !ok {
+		return nil
+	}
+
+	var poss [][]int
+	for start := max(lN-window+1, 0); start <= lN; start++ {
+		var b strings.Builder
+		first, offset := 0, 0
+		for n := start; n < start+window; n++ {
+			s, ok := lineStr(n)
+			if !ok {
+				break
+			}
+			if n > start {
+				b.WriteByte('\n')
+			}
+			if n == start {
+				first = len(s)
+			}
+			if n == lN {
+				offset = b.Len()
+			}
+			b.WriteString(s)
+		}
+		end := offset + len(line)
+		for _, loc := range root.searchReg.FindAllStringIndex(b.String(), -1) {
+			if loc[0] > first {
+				break
+			}
+			left, right := max(loc[0], offset), min(loc[1], end)
+			if left < right {
+				poss = append(poss, []int{left - offset, right - offset})
+			}
+		}
+	}
+	return poss
+}
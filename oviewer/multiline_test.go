@@ -0,0 +1,126 @@
+package oviewer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMultiline = "info start\n" +
+	"Exception: failed\n" +
+	"\tat main.go:10\n" +
+	"\tat run.go:20\n" +
+	"info end\n" +
+	"Exception: again\n" +
+	"info last\n"
+
+func testMultilineRoot(t *testing.T, word string, window int, regexpSearch bool) (*Root, Searcher) {
+	t.Helper()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(testMultiline)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	root := &Root{
+		Doc:   m,
+		input: &Input{},
+		Config: Config{
+			RegexpSearch:    regexpSearch,
+			MultilineSearch: true,
+			MultilineWindow: window,
+		},
+	}
+	return root, root.setSearcher(word, false)
+}
+
+func TestDocument_matchLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		word    string
+		window  int
+		literal bool
+		want    []bool
+	}{
+		{
+			name:   "testStackTrace",
+			word:   `Exception.*\n\tat`,
+			window: 3,
+			want:   []bool{false, true, false, false, false, false, false},
+		},
+		{
+			name:   "testWindow",
+			word:   `failed\n.*\n.*run\.go`,
+			window: 2,
+			want:   []bool{false, false, false, false, false, false, false},
+		},
+		{
+			name:   "testSingleLine",
+			word:   `^info`,
+			window: 0,
+			want:   []bool{true, false, false, false, true, false, true},
+		},
+		{
+			name:    "testLiteral",
+			word:    `info.*`,
+			window:  3,
+			literal: true,
+			want:    []bool{false, false, false, false, false, false, false},
+		},
+		{
+			name:    "testLiteralMatch",
+			word:    `main.go:10`,
+			window:  3,
+			literal: true,
+			want:    []bool{false, false, true, false, false, false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, searcher := testMultilineRoot(t, tt.word, tt.window, !tt.literal)
+			var got []bool
+			for n := 0; n < root.Doc.BufEndNum(); n++ {
+				got = append(got, root.Doc.matchLine(searcher, n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.matchLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_SearchLineMultiline(t *testing.T) {
+	root, searcher := testMultilineRoot(t, `Exception.*\n\tat`, 0, true)
+	got, err := root.Doc.SearchLine(context.Background(), searcher, 2)
+	if err != ErrNotFound {
+		t.Errorf("Document.SearchLine() = %v, %v, want %v", got, err, ErrNotFound)
+	}
+	got, err = root.Doc.BackSearchLine(context.Background(), searcher, 3)
+	if err != nil || got != 1 {
+		t.Errorf("Document.BackSearchLine() = %v, %v, want 1", got, err)
+	}
+}
+
+func TestRoot_multilinePosition(t *testing.T) {
+	root, _ := testMultilineRoot(t, `failed\n.*\n\tat run`, 0, true)
+	tests := []struct {
+		name string
+		lN   int
+		want [][]int
+	}{
+		{name: "testFirst", lN: 1, want: [][]int{{11, 17}}},
+		{name: "testMiddle", lN: 2, want: [][]int{{0, 14}}},
+		{name: "testLast", lN: 3, want: [][]int{{0, 7}}},
+		{name: "testNone", lN: 4, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := root.multilinePosition(tt.lN); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Root.multilinePosition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
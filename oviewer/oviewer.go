@@ -52,6 +52,12 @@ type Root struct {
 	searchWord string
 	// searchReg for on-screen highlighting.
 	searchReg *regexp.Regexp
+	// searchAll is true if the search continues into the other documents.
+	searchAll bool
+	// fuzzy is the lines of the fuzzy search in score order.
+	fuzzy *fuzzyResult
+	// highlights is a list of highlights independent of the search.
+	highlights []highlight
 
 	// keyConfig contains the binding settings for the key.
 	keyConfig *cbind.Configuration
@@ -93,6 +99,9 @@ type Root struct {
 	// headerLen is the actual header length when wrapped.
 	headerLen int
 
+	// columnsKey is the state that the columns were last computed with.
+	columnsKey columnsKey
+
 	// statusPos is the position of the status line.
 	statusPos int
 	// minStartX is the minimum start position of x.
@@ -125,14 +134,32 @@ type general struct {
 	LineNumMode bool
 	// Wrap is Wrap mode.
 	WrapMode bool
+	// HexMode displays the raw bytes in hex like xxd.
+	HexMode bool
 	// ColumnDelimiter is a column delimiter.
 	ColumnDelimiter string
+	// ColumnWidth splits the columns by the widths inferred from the header and the vertical gaps,
+	// instead of the delimiter.
+	ColumnWidth bool
+	// ColumnCSV splits the columns as CSV (RFC 4180) with the quoted fields.
+	ColumnCSV bool
+	// AlignMode displays the columns aligned with the separators.
+	AlignMode bool
+	// ColumnLayout is the columns to display in order, by the header names or the column numbers.
+	// The other columns are hidden.
+	ColumnLayout []string
+	// PinColumns is the number of the columns pinned on the left while scrolling horizontally.
+	PinColumns int
+	// DetectDelimiter detects the delimiter and enables column mode if the document looks like a table.
+	DetectDelimiter bool
 	// FollowMode is the follow mode.
 	FollowMode bool
 	// FollowAll is a follow mode for all documents.
 	FollowAll bool
 	// FollowSection is a follow mode that uses section instead of line.
 	FollowSection bool
+	// FollowName is a follow mode by file name instead of file descriptor.
+	FollowName bool
 	// WatchInterval is the watch interval (seconds).
 	WatchInterval int
 	// MarkStyleWidth is width to apply the style of the marked line.
@@ -143,6 +170,9 @@ type general struct {
 	SectionDelimiterReg *regexp.Regexp
 	// SectionStartPosition is a section start position.
 	SectionStartPosition int
+	// Encoding is the character encoding of the input.
+	// The encoding detected by BOM takes precedence.
+	Encoding string
 }
 
 // Config represents the settings of ov.
@@ -167,6 +197,11 @@ type Config struct {
 	StyleMarkLine OVStyle
 	// StyleSectionLine is a style that section delimiter line.
 	StyleSectionLine OVStyle
+	// StyleHighlights are the styles that apply in turn to the highlights added interactively.
+	StyleHighlights []OVStyle
+
+	// Highlight is a list of patterns to highlight.
+	Highlight []HighlightRule
 
 	// General represents the general behavior.
 	General general
@@ -190,8 +225,27 @@ type Config struct {
 	CaseSensitive bool
 	// RegexpSearch is Regular expression search if true.
 	RegexpSearch bool
+	// SmartCase is case-insensitive unless the search word contains uppercase if true.
+	SmartCase bool
+	// WholeWord matches only the whole words if true.
+	WholeWord bool
+	// WildcardSearch is a literal search with the wildcards (* and ?) if true.
+	WildcardSearch bool
+	// ColumnSearch restricts the search to the highlighted column in column mode if true.
+	ColumnSearch bool
+	// FuzzySearch is a fuzzy search that moves to the lines in order of the score if true.
+	FuzzySearch bool
+	// MultilineSearch is a regular expression search that matches across the lines if true.
+	MultilineSearch bool
+	// MultilineWindow is the number of lines that a match of the multi-line search can span.
+	// 0 is 10 lines.
+	MultilineWindow int
 	// Incsearch is incremental server if true.
 	Incsearch bool
+	// DisableHistory does not save the input history across sessions if true.
+	DisableHistory bool
+	// HistorySize is the maximum number of entries of each input history.
+	HistorySize int
 	// Debug represents whether to enable the debug output.
 	Debug bool
 
@@ -284,6 +338,24 @@ var (
 	ErrSignalCatch = errors.New("signal catch")
 	// ErrAlreadyClose indicates that it is already closed.
 	ErrAlreadyClose = errors.New("already closed")
+	// ErrTruncated indicates that the file has been truncated.
+	ErrTruncated = errors.New("truncated")
+	// ErrRotated indicates that the file has been rotated.
+	ErrRotated = errors.New("rotated")
+	// ErrUnknownEncoding indicates that the encoding is unknown.
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	// ErrInvalidHeader indicates that the header of the compressed format is invalid.
+	ErrInvalidHeader = errors.New("invalid header")
+	// ErrCorrupt indicates that the compressed data is corrupt.
+	ErrCorrupt = errors.New("corrupt compressed data")
+	// ErrNotArchive indicates that the document is not an archive.
+	ErrNotArchive = errors.New("not an archive")
+	// ErrNotFiltered indicates that the document is not a filtered document.
+	ErrNotFiltered = errors.New("not a filtered document")
+	// ErrNoColumn indicates that there is no column of the name.
+	ErrNoColumn = errors.New("no column")
+	// ErrInvalidSort indicates that the sort option is invalid.
+	ErrInvalidSort = errors.New("invalid sort option")
 )
 
 // This is a function of tcell.NewScreen but can be replaced with mock.
@@ -354,6 +426,14 @@ func NewConfig() Config {
 		StyleSectionLine: OVStyle{
 			Background: "green",
 		},
+		StyleHighlights: []OVStyle{
+			{Foreground: "black", Background: "yellow"},
+			{Foreground: "black", Background: "aqua"},
+			{Foreground: "black", Background: "lime"},
+			{Foreground: "black", Background: "fuchsia"},
+			{Foreground: "black", Background: "orange"},
+			{Foreground: "black", Background: "silver"},
+		},
 		General: general{
 			TabWidth:             8,
 			MarkStyleWidth:       1,
@@ -502,6 +582,7 @@ func (root *Root) SetKeyHandler(name string, keys []string, handler func()) erro
 // Run starts the terminal pager.
 func (root *Root) Run() error {
 	defer root.Close()
+	defer root.saveHistory()
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -526,11 +607,13 @@ func (root *Root) Run() error {
 		root.Screen.EnableMouse()
 	}
 	root.Config.General.SectionDelimiterReg = regexpCompile(root.Config.General.SectionDelimiter, true)
+	root.setHighlights()
 
 	root.optimizedMan()
 
 	for n, doc := range root.DocList {
 		doc.general = root.Config.General
+		doc.setHexMode()
 		w := ""
 		if doc.general.WatchInterval > 0 {
 			doc.watchMode()
@@ -597,6 +680,12 @@ func (root *Root) setModeConfig() {
 	for name := range root.Config.Mode {
 		list = append(list, name)
 	}
+	// Keep the order of the history.
+	for _, name := range root.input.ModeCandidate.list {
+		if containsStr(list, name) {
+			list = toLast(list, name)
+		}
+	}
 	root.input.ModeCandidate.list = list
 }
 
@@ -690,11 +779,19 @@ func overwriteGeneral(a general, b general) general {
 	}
 	a.AlternateRows = b.AlternateRows
 	a.ColumnMode = b.ColumnMode
+	a.ColumnWidth = b.ColumnWidth
+	a.ColumnCSV = b.ColumnCSV
+	a.AlignMode = b.AlignMode
+	a.DetectDelimiter = b.DetectDelimiter
+	a.ColumnLayout = b.ColumnLayout
+	a.PinColumns = b.PinColumns
 	a.LineNumMode = b.LineNumMode
 	a.WrapMode = b.WrapMode
+	a.HexMode = b.HexMode
 	a.FollowMode = b.FollowMode
 	a.FollowAll = b.FollowAll
 	a.FollowSection = b.FollowSection
+	a.FollowName = b.FollowName
 	if b.ColumnDelimiter != "" {
 		a.ColumnDelimiter = b.ColumnDelimiter
 	}
@@ -710,6 +807,9 @@ func overwriteGeneral(a general, b general) general {
 	if b.SectionStartPosition != 0 {
 		a.SectionStartPosition = b.SectionStartPosition
 	}
+	if b.Encoding != "" {
+		a.Encoding = b.Encoding
+	}
 	return a
 }
 
@@ -737,7 +837,7 @@ func (root *Root) docSmall() bool {
 		return false
 	}
 	hight := 0
-	for y := 0; y < m.BufEndNum(); y++ {
+	for y := 0; y < m.viewEndNum(); y++ {
 		lc, err := m.contentsLN(y, root.Doc.TabWidth)
 		if err != nil {
 			log.Printf("docSmall %d: %s", y, err)
@@ -755,7 +855,7 @@ func (root *Root) docSmall() bool {
 func (root *Root) WriteOriginal() {
 	m := root.Doc
 	if m.bottomLN == 0 {
-		m.bottomLN = m.BufEndNum()
+		m.bottomLN = m.viewEndNum()
 	}
 
 	start := max(0, m.topLN-root.BeforeWriteOriginal)
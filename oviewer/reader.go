@@ -5,18 +5,27 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"math/bits"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
+	"time"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/snappy"
 	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4"
 	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
 	"golang.org/x/term"
 )
 
@@ -36,22 +45,88 @@ const (
 	LZ4
 	// XZ is xz compressed format.
 	XZ
+	// BROTLI is brotli compressed format.
+	BROTLI
+	// SNAPPY is snappy framing format.
+	SNAPPY
+	// ZLIB is zlib compressed format.
+	ZLIB
+	// LZMA is lzma (lzma_alone) compressed format.
+	LZMA
+	// LZIP is lzip compressed format.
+	LZIP
 )
 
 const FormFeed = "\f"
 
+// FollowCheckInterval is the interval to check the file
+// for truncation and rotation in follow mode.
+var FollowCheckInterval = time.Second
+
+// compressHeaderLen is the length of the header needed to detect the compression.
+// It is the length of the header of lzma (lzma_alone).
+const compressHeaderLen = 13
+
 func compressType(header []byte) Compressed {
 	switch {
-	case bytes.Equal(header[:3], []byte{0x1f, 0x8b, 0x8}):
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b, 0x8}):
 		return GZIP
-	case bytes.Equal(header[:3], []byte{0x42, 0x5A, 0x68}):
+	case bytes.HasPrefix(header, []byte{0x42, 0x5A, 0x68}):
 		return BZIP2
-	case bytes.Equal(header[:4], []byte{0x28, 0xb5, 0x2f, 0xfd}):
+	case bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd}):
 		return ZSTD
-	case bytes.Equal(header[:4], []byte{0x04, 0x22, 0x4d, 0x18}):
+	case bytes.HasPrefix(header, []byte{0x04, 0x22, 0x4d, 0x18}):
 		return LZ4
-	case bytes.Equal(header[:7], []byte{0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x0, 0x0}):
+	case bytes.HasPrefix(header, []byte{0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x0, 0x0}):
 		return XZ
+	case bytes.HasPrefix(header, []byte{0xff, 0x06, 0x00, 0x00, 's', 'N', 'a', 'P', 'p', 'Y'}):
+		return SNAPPY
+	case bytes.HasPrefix(header, []byte("LZIP\x01")):
+		return LZIP
+	case isLZMAHeader(header):
+		return LZMA
+	// Only deflate with a 32K window and a valid header checksum, to avoid mistaking text for zlib.
+	case len(header) >= 2 && header[0] == 0x78 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0:
+		return ZLIB
+	}
+	return UNCOMPRESSED
+}
+
+// isLZMAHeader returns true if the header is the lzma (lzma_alone) header
+// with the default properties (lc=3, lp=0, pb=2).
+// The dictionary size and the uncompressed size are also checked like xz,
+// to avoid mistaking text such as UTF-32LE starting with "]" for lzma.
+func isLZMAHeader(header []byte) bool {
+	if len(header) < compressHeaderLen || header[0] != 0x5d {
+		return false
+	}
+	// The dictionary size is 2^n or 2^n+2^(n-1) of 4KiB or more.
+	dictSize := binary.LittleEndian.Uint32(header[1:5])
+	if dictSize < 1<<12 {
+		return false
+	}
+	if high := uint32(1) << (bits.Len32(dictSize) - 1); dictSize != math.MaxUint32 && dictSize != high && dictSize != high|high>>1 {
+		return false
+	}
+	// The uncompressed size is unknown or less than 256GiB.
+	size := binary.LittleEndian.Uint64(header[5:13])
+	return size == math.MaxUint64 || size < 1<<38
+}
+
+// compressExtType returns the type of compression from the file extension.
+// It is used for formats that cannot be detected by the header.
+func compressExtType(fileName string) Compressed {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".br":
+		return BROTLI
+	case ".sz":
+		return SNAPPY
+	case ".zz", ".zlib":
+		return ZLIB
+	case ".lzma":
+		return LZMA
+	case ".lz":
+		return LZIP
 	}
 	return UNCOMPRESSED
 }
@@ -68,12 +143,25 @@ func (c Compressed) String() string {
 		return "LZ4"
 	case XZ:
 		return "XZ"
+	case BROTLI:
+		return "BROTLI"
+	case SNAPPY:
+		return "SNAPPY"
+	case ZLIB:
+		return "ZLIB"
+	case LZMA:
+		return "LZMA"
+	case LZIP:
+		return "LZIP"
 	}
 	return "UNCOMPRESSED"
 }
 
-func uncompressedReader(reader io.Reader) (Compressed, io.Reader) {
-	buf := [7]byte{}
+// uncompressedReader returns the type of compression and the uncompressed reader.
+// If the compression is not detected by the header,
+// it is determined by the extension of fileName.
+func uncompressedReader(reader io.Reader, fileName string) (Compressed, io.Reader) {
+	buf := [compressHeaderLen]byte{}
 	n, err := io.ReadAtLeast(reader, buf[:], len(buf))
 	if err != nil {
 		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
@@ -82,13 +170,42 @@ func uncompressedReader(reader io.Reader) (Compressed, io.Reader) {
 		return UNCOMPRESSED, bytes.NewReader(nil)
 	}
 
-	mr := io.MultiReader(bytes.NewReader(buf[:n]), reader)
-	cFormat := compressType(buf[:7])
+	var mr io.Reader = io.MultiReader(bytes.NewReader(buf[:n]), reader)
+	cFormat := compressType(buf[:n])
+	if cFormat == UNCOMPRESSED {
+		cFormat = compressExtType(fileName)
+	}
+	if cFormat == ZLIB {
+		br := bufio.NewReaderSize(mr, zlibCheckLen)
+		if !zlibReadable(br) {
+			return UNCOMPRESSED, br
+		}
+		mr = br
+	}
 	r := compressedFormatReader(cFormat, mr)
 
 	return cFormat, r
 }
 
+// zlibCheckLen is the length of the data to check that it can be read as zlib.
+const zlibCheckLen = 4096
+
+// zlibReadable returns true if the first read of zlib succeeds.
+// The zlib header is short and may appear at the beginning of the text.
+func zlibReadable(br *bufio.Reader) bool {
+	b, err := br.Peek(zlibCheckLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+	// The data may be cut in the middle.
+	_, err = zr.Read(make([]byte, 1))
+	return err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
 func compressedFormatReader(cFormat Compressed, reader io.Reader) io.Reader {
 	var r io.Reader
 	var err error
@@ -103,6 +220,16 @@ func compressedFormatReader(cFormat Compressed, reader io.Reader) io.Reader {
 		r = lz4.NewReader(reader)
 	case XZ:
 		r, err = xz.NewReader(reader)
+	case BROTLI:
+		r = brotli.NewReader(reader)
+	case SNAPPY:
+		r = snappy.NewReader(reader)
+	case ZLIB:
+		r, err = zlib.NewReader(reader)
+	case LZMA:
+		r, err = lzma.NewReader(reader)
+	case LZIP:
+		r, err = lzipReader(reader)
 	}
 	if err != nil || r == nil {
 		r = reader
@@ -110,6 +237,29 @@ func compressedFormatReader(cFormat Compressed, reader io.Reader) io.Reader {
 	return r
 }
 
+// lzipReader returns a reader of the first member of lzip.
+// The lzip header is converted to the lzma header,
+// because the member is an lzma stream with the end marker.
+func lzipReader(reader io.Reader) (io.Reader, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:5], []byte("LZIP\x01")) {
+		return nil, ErrInvalidHeader
+	}
+	// The dictionary size is a power of 2 minus 0 to 7 sixteenths of it.
+	dictSize := uint32(1) << (header[5] & 0x1f)
+	dictSize -= dictSize / 16 * uint32(header[5]>>5)
+
+	lzmaHeader := make([]byte, 13)
+	lzmaHeader[0] = 0x5d // lc=3, lp=0, pb=2
+	binary.LittleEndian.PutUint32(lzmaHeader[1:5], dictSize)
+	// Unknown size.
+	binary.LittleEndian.PutUint64(lzmaHeader[5:], math.MaxUint64)
+	return lzma.NewReader(io.MultiReader(bytes.NewReader(lzmaHeader), reader))
+}
+
 // ReadFile reads file.
 func (m *Document) ReadFile(fileName string) error {
 	m.mu.Lock()
@@ -129,12 +279,34 @@ func (m *Document) ReadFile(fileName string) error {
 		m.file = r
 	}
 
-	cFormat, r := uncompressedReader(m.file)
+	cFormat, r := uncompressedReader(m.file, fileName)
 	m.CFormat = cFormat
+	m.archive = nil
+	format := NOARCHIVE
+	if m.seekable && fileName != "" {
+		br := bufio.NewReader(r)
+		format = peekArchive(br)
+		r = br
+	}
+	if format == NOARCHIVE {
+		if err := m.openIndex(fileName); err != nil {
+			log.Printf("ReadFile: %s", err)
+		}
+		// Read again from the beginning to record the checkpoints.
+		if m.index != nil && m.index.seek != nil {
+			if _, err := m.file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			r = m.index.seek.record(m.file)
+		}
+	}
 
 	go func() {
 		<-m.eofCh
-		if m.seekable {
+		// The file is not kept open at EOF.
+		// In follow mode, it is reopened at the last position and followed by descriptor.
+		// The members of the zip archive are read from the file.
+		if m.seekable && format != ZIP {
 			if err := m.close(); err != nil {
 				log.Printf("ReadFile: %s", err)
 			}
@@ -142,6 +314,10 @@ func (m *Document) ReadFile(fileName string) error {
 		atomic.StoreInt32(&m.changed, 1)
 		m.followCh <- struct{}{}
 	}()
+	if format != NOARCHIVE {
+		// The listing of members is read instead of the contents.
+		return m.readArchive(format, r)
+	}
 	if STDOUTPIPE != nil {
 		r = io.TeeReader(r, STDOUTPIPE)
 	}
@@ -164,15 +340,22 @@ func (m *Document) ReadReader(r io.Reader) error {
 // ReadAll needs to be notified on eofCh.
 func (m *Document) ReadAll(r io.Reader) error {
 	reader := bufio.NewReader(r)
+	encoding := m.Encoding
+	if encoding == "" {
+		encoding = DefaultEncoding
+	}
 	go func() {
 		if m.checkClose() {
 			return
 		}
+		reader = m.decodeReader(reader, encoding)
 
 		if err := m.readAll(reader); err != nil {
 			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed) {
 				m.eofCh <- struct{}{}
 				atomic.StoreInt32(&m.eof, 1)
+				// Notify again after EOF to switch from the tail.
+				atomic.StoreInt32(&m.changed, 1)
 				return
 			}
 			log.Printf("error: %v\n", err)
@@ -204,12 +387,20 @@ func (m *Document) onceFollowMode() {
 	m.cancel = cancel
 }
 
-// startFollowMode opens the file in follow mode.
-// Seek to the position where the file was closed, and then read.
+// startFollowMode starts reading the file in follow mode.
+// The file continues to be read from the descriptor.
+// If the file is closed, seek to the position where the file was closed,
+// and then read.
+// If the file is truncated, the document is reset and read from the beginning.
+// In FollowName mode, if the file is rotated, the new file is read.
 func (m *Document) startFollowMode(ctx context.Context, cancel context.CancelFunc) {
 	defer cancel()
 	<-m.followCh
-	if m.seekable {
+	if m.ArchiveFormat() != NOARCHIVE {
+		// The listing of the archive is not followed.
+		return
+	}
+	if m.seekable && m.checkClose() {
 		// Wait for the file to open until it changes.
 		select {
 		case <-ctx.Done():
@@ -218,11 +409,100 @@ func (m *Document) startFollowMode(ctx context.Context, cancel context.CancelFun
 		}
 		m.file = m.openFollowFile()
 	}
+	atomic.StoreInt32(&m.eof, 0)
+
+	for {
+		r := compressedFormatReader(m.CFormat, m.file)
+		err := m.ContinueReadAll(ctx, r)
+		switch {
+		case errors.Is(err, ErrTruncated):
+			log.Printf("%s truncated", m.FileName)
+			m.followTruncated()
+			continue
+		case errors.Is(err, ErrRotated):
+			log.Printf("%s rotated", m.FileName)
+			if err := m.followRotated(); err != nil {
+				log.Printf("%s follow mode open %v", m.FileName, err)
+				return
+			}
+			continue
+		case err != nil:
+			log.Printf("%s follow mode read %v", m.FileName, err)
+		}
+		return
+	}
+}
+
+// followCheck checks whether the file being followed has been truncated or rotated.
+func (m *Document) followCheck() error {
+	if !m.seekable || m.file == nil {
+		return nil
+	}
+
+	fi, err := m.file.Stat()
+	if err != nil {
+		return nil
+	}
+	pos, err := m.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	if fi.Size() < pos {
+		return ErrTruncated
+	}
+
+	if !m.FollowName {
+		return nil
+	}
+	// The file may not have been created yet after rotation.
+	nfi, err := os.Stat(m.FileName)
+	if err != nil {
+		return nil
+	}
+	if !os.SameFile(fi, nfi) {
+		return ErrRotated
+	}
+	return nil
+}
 
-	r := compressedFormatReader(m.CFormat, m.file)
-	if err := m.ContinueReadAll(ctx, r); err != nil {
-		log.Printf("%s follow mode read %v", m.FileName, err)
+// followTruncated resets the document and reads the truncated file from the beginning.
+func (m *Document) followTruncated() {
+	if _, err := m.file.Seek(0, io.SeekStart); err != nil {
+		log.Printf("followTruncated: %s", err)
+	}
+	m.reset()
+	m.mu.Lock()
+	// The sealed index is a rotated file.
+	if m.index != nil && m.index.sealed {
+		if err := m.closeIndex(); err != nil {
+			log.Printf("followTruncated: %s", err)
+		}
 	}
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.truncated, 1)
+}
+
+// followRotated opens the new file with the same name and continues reading.
+// The lines of the old file are kept.
+func (m *Document) followRotated() error {
+	f, err := os.Open(m.FileName)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if err := m.file.Close(); err != nil {
+		log.Printf("followRotated: %s", err)
+	}
+	m.file = f
+	// The lines of the new file are stored in memory.
+	if m.index != nil {
+		m.index.sealed = true
+	}
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.rotated, 1)
+	atomic.StoreInt32(&m.changed, 1)
+	return nil
 }
 
 // openFollowFile opens a file in follow mode.
@@ -267,7 +547,8 @@ func (m *Document) close() error {
 
 // ContinueReadAll continues to read even if it reaches EOF.
 func (m *Document) ContinueReadAll(ctx context.Context, r io.Reader) error {
-	reader := bufio.NewReader(r)
+	reader := bufio.NewReader(m.continueDecodeReader(r))
+	waited := false
 	for {
 		select {
 		case <-ctx.Done():
@@ -278,33 +559,67 @@ func (m *Document) ContinueReadAll(ctx context.Context, r io.Reader) error {
 			return nil
 		}
 
+		m.mu.Lock()
+		start := m.endNum
+		m.mu.Unlock()
 		if err := m.readAll(reader); err != nil {
-			if errors.Is(err, io.EOF) {
-				<-m.changCh
-				continue
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			if err := m.followCheck(); err != nil {
+				return err
+			}
+			// The truncation or the rotation is over
+			// when the lines added after it are read successfully.
+			m.mu.Lock()
+			added := m.endNum > start
+			m.mu.Unlock()
+			if waited && added {
+				atomic.StoreInt32(&m.truncated, 0)
+				atomic.StoreInt32(&m.rotated, 0)
+			}
+			waited = true
+			// Check regularly because the rotated file is not notified.
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-m.changCh:
+			case <-time.After(FollowCheckInterval):
 			}
-			return err
 		}
 	}
 }
 
 // readAll actually reads everything.
 // The read lines are stored in the lines of the Document.
+// If the Document has a line index, only the size of the line is stored.
 func (m *Document) readAll(reader *bufio.Reader) error {
 	var line strings.Builder
+	size := 0
 
 	for {
-		buf, isPrefix, err := reader.ReadLine()
+		buf, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			size += len(buf)
+			if !m.indexing() {
+				line.Write(buf)
+			}
+			continue
+		}
+		if len(buf) > 0 || size > 0 {
+			size += len(buf)
+			if m.indexing() {
+				m.appendIndex(size)
+			} else {
+				line.Write(buf)
+				m.append(trimLineEnd(line.String()))
+				line.Reset()
+			}
+			size = 0
+		}
 		if err != nil {
 			return err
 		}
-		line.Write(buf)
-		if isPrefix {
-			continue
-		}
-
-		m.append(line.String())
-		line.Reset()
 	}
 }
 
@@ -319,13 +634,20 @@ func (m *Document) append(lines ...string) {
 	atomic.StoreInt32(&m.changed, 1)
 }
 
+// appendIndex appends a line of size bytes to the line index.
+func (m *Document) appendIndex(size int) {
+	m.mu.Lock()
+	m.index.add(size)
+	m.endNum++
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.changed, 1)
+}
+
 func (m *Document) appendFormFeed() {
 	line := ""
-	m.mu.Lock()
-	if m.endNum > 0 {
-		line = m.lines[m.endNum-1]
+	if m.BufEndNum() > 0 {
+		line = m.GetLine(m.BufEndNum() - 1)
 	}
-	m.mu.Unlock()
 
 	// Do not add if the previous is FormFeed.
 	if line != FormFeed {
@@ -341,6 +663,16 @@ func (m *Document) reload() error {
 		return fmt.Errorf("%w %s", ErrAlreadyClose, m.FileName)
 	}
 
+	if m.hexMode() {
+		// The raw bytes are prepared again after reopening.
+		m.mu.Lock()
+		if err := m.closeHex(); err != nil {
+			log.Println(err)
+		}
+		m.mu.Unlock()
+		defer m.setHexMode()
+	}
+
 	if m.seekable {
 		if m.cancel != nil {
 			m.cancel()
@@ -353,6 +685,11 @@ func (m *Document) reload() error {
 	}
 
 	if m.WatchMode {
+		// The lines of the previous read are kept,
+		// so they can no longer be read from the file.
+		if err := m.loadIndex(); err != nil {
+			return err
+		}
 		m.appendFormFeed()
 	} else {
 		m.reset()
@@ -364,6 +701,7 @@ func (m *Document) reload() error {
 	}
 
 	atomic.StoreInt32(&m.closed, 0)
+	atomic.StoreInt32(&m.eof, 0)
 	return m.ReadFile(m.FileName)
 }
 
@@ -372,6 +710,10 @@ func (m *Document) reset() {
 	m.mu.Lock()
 	m.endNum = 0
 	m.lines = m.lines[:0]
+	if m.index != nil {
+		m.index.reset()
+	}
+	m.tail = nil
 	m.mu.Unlock()
 	atomic.StoreInt32(&m.changed, 1)
 	m.ClearCache()
@@ -1,9 +1,21 @@
 package oviewer
 
 import (
+	"bufio"
 	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/snappy"
+	"github.com/ulikunitz/xz/lzma"
 )
 
 func TestDocument_ReadFile(t *testing.T) {
@@ -72,3 +84,409 @@ func TestDocument_ReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestDocument_followCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		followName bool
+		change     func(fileName string) error
+		wantErr    error
+	}{
+		{
+			name:       "testNoChange",
+			followName: true,
+			change: func(fileName string) error {
+				return nil
+			},
+			wantErr: nil,
+		},
+		{
+			name:       "testTruncated",
+			followName: false,
+			change: func(fileName string) error {
+				return os.Truncate(fileName, 0)
+			},
+			wantErr: ErrTruncated,
+		},
+		{
+			name:       "testRotated",
+			followName: true,
+			change: func(fileName string) error {
+				if err := os.Rename(fileName, fileName+".1"); err != nil {
+					return err
+				}
+				return os.WriteFile(fileName, []byte("new\n"), 0o600)
+			},
+			wantErr: ErrRotated,
+		},
+		{
+			name:       "testRotatedDescriptor",
+			followName: false,
+			change: func(fileName string) error {
+				if err := os.Rename(fileName, fileName+".1"); err != nil {
+					return err
+				}
+				return os.WriteFile(fileName, []byte("new\n"), 0o600)
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "follow.log")
+			if err := os.WriteFile(fileName, []byte("old1\nold2\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m, err := OpenDocument(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.closeIndex()
+			// The file is closed at EOF and reopened at the last position in follow mode.
+			for !m.checkClose() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			m.file = m.openFollowFile()
+			m.FollowName = tt.followName
+
+			if err := tt.change(fileName); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.followCheck(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Document.followCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocument_followRotated(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "follow.log")
+	if err := os.WriteFile(fileName, []byte("old1\nold2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, []byte("new1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.followRotated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.readAll(bufio.NewReader(m.file)); !errors.Is(err, io.EOF) {
+		t.Fatal(err)
+	}
+
+	want := []string{"old1", "old2", "new1"}
+	if got := m.BufEndNum(); got != len(want) {
+		t.Fatalf("Document.BufEndNum() = %v, want %v", got, len(want))
+	}
+	for n, w := range want {
+		if got := m.GetLine(n); got != w {
+			t.Errorf("Document.GetLine(%d) = %v, want %v", n, got, w)
+		}
+	}
+}
+
+func TestDocument_ReadFileClose(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "close.log")
+	if err := os.WriteFile(fileName, []byte("line1\nline2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	// The file is not kept open at EOF unless it is followed.
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.checkClose() {
+		if time.Now().After(deadline) {
+			t.Fatal("Document.ReadFile() does not close the file at EOF")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if m.offset != 12 {
+		t.Errorf("Document.offset = %v, want %v", m.offset, 12)
+	}
+}
+
+func TestDocument_followTruncated(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "follow.log")
+	if err := os.WriteFile(fileName, []byte("old1\nold2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	// The file is closed at EOF and reopened at the last position in follow mode.
+	for !m.checkClose() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.file = m.openFollowFile()
+	if err := os.WriteFile(fileName, []byte("new1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m.followTruncated()
+	if atomic.LoadInt32(&m.truncated) != 1 || atomic.LoadInt32(&m.rotated) != 0 {
+		t.Fatalf("Document.followTruncated() truncated, rotated = %d, %d, want 1, 0",
+			atomic.LoadInt32(&m.truncated), atomic.LoadInt32(&m.rotated))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = m.ContinueReadAll(ctx, m.file)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	for m.BufEndNum() < 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&m.truncated) != 1 {
+		t.Errorf("Document.ContinueReadAll() cleared truncated before it is displayed")
+	}
+
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("new2\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&m.truncated) == 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&m.truncated) != 0 {
+		t.Errorf("Document.ContinueReadAll() truncated = 1, want 0")
+	}
+}
+
+func Test_compressType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Compressed
+	}{
+		{
+			name:   "testZlib",
+			header: []byte{0x78, 0x9c, 0x4b, 0x4c},
+			want:   ZLIB,
+		},
+		{
+			name:   "testZlibChecksum",
+			header: []byte("xy text"),
+			want:   UNCOMPRESSED,
+		},
+		{
+			name:   "testGzip",
+			header: []byte{0x1f, 0x8b, 0x8, 0x0},
+			want:   GZIP,
+		},
+		{
+			name:   "testLZMA",
+			header: []byte{0x5d, 0x00, 0x00, 0x80, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00},
+			want:   LZMA,
+		},
+		{
+			name:   "testLZMASize",
+			header: []byte{0x5d, 0x00, 0x00, 0x00, 0x01, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want:   LZMA,
+		},
+		{
+			// "]@ab" in UTF-32LE.
+			name:   "testUTF32Text",
+			header: []byte{0x5d, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x61, 0x00, 0x00, 0x00, 0x62, 0x00},
+			want:   UNCOMPRESSED,
+		},
+		{
+			name:   "testLZMAShort",
+			header: []byte{0x5d, 0x00, 0x00, 0x80, 0x00},
+			want:   UNCOMPRESSED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compressType(tt.header); got != tt.want {
+				t.Errorf("compressType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_ReadFileZlibText(t *testing.T) {
+	// "x^" is a valid zlib header, but the text is not zlib.
+	str := "x^2 + y^2\nline2\n"
+	fileName := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(fileName, []byte(str), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if m.CFormat != UNCOMPRESSED {
+		t.Errorf("Document.CFormat = %v, want %v", m.CFormat, UNCOMPRESSED)
+	}
+	if got := m.GetLine(0); got != "x^2 + y^2" {
+		t.Errorf("Document.GetLine(0) = %v, want %v", got, "x^2 + y^2")
+	}
+}
+
+func Test_compressExtType(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     Compressed
+	}{
+		{
+			name:     "testBrotli",
+			fileName: "test.txt.br",
+			want:     BROTLI,
+		},
+		{
+			name:     "testUpper",
+			fileName: "TEST.LZMA",
+			want:     LZMA,
+		},
+		{
+			name:     "testText",
+			fileName: "test.txt",
+			want:     UNCOMPRESSED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compressExtType(tt.fileName); got != tt.want {
+				t.Errorf("compressExtType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testCompress compresses str in the format.
+func testCompress(t *testing.T, cFormat Compressed, str string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch cFormat {
+	case BROTLI:
+		w = brotli.NewWriter(&buf)
+	case SNAPPY:
+		w = snappy.NewBufferedWriter(&buf)
+	case ZLIB:
+		w = zlib.NewWriter(&buf)
+	case LZMA:
+		w, err = lzma.NewWriter(&buf)
+	case LZIP:
+		// An lzip member is an lzma stream with the end marker
+		// between the lzip header and the trailer.
+		var lz bytes.Buffer
+		w, err = lzma.WriterConfig{DictCap: 1 << 23, EOSMarker: true, Size: -1}.NewWriter(&lz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, str); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("LZIP\x01\x17")
+		buf.Write(lz.Bytes()[13:])
+		buf.Write(make([]byte, 20))
+		return buf.Bytes()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, str); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocument_ReadFileCompressed(t *testing.T) {
+	str := "compressed line1\ncompressed line2\n"
+	tests := []struct {
+		name     string
+		fileName string
+		cFormat  Compressed
+	}{
+		{
+			name:     "testBrotli",
+			fileName: "test.txt.br",
+			cFormat:  BROTLI,
+		},
+		{
+			name:     "testSnappy",
+			fileName: "test.txt.sz",
+			cFormat:  SNAPPY,
+		},
+		{
+			name:     "testSnappyMagic",
+			fileName: "test.txt",
+			cFormat:  SNAPPY,
+		},
+		{
+			name:     "testZlib",
+			fileName: "test.txt",
+			cFormat:  ZLIB,
+		},
+		{
+			name:     "testLzma",
+			fileName: "test.txt.lzma",
+			cFormat:  LZMA,
+		},
+		{
+			name:     "testLzip",
+			fileName: "test.txt",
+			cFormat:  LZIP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(fileName, testCompress(t, tt.cFormat, str), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m, err := OpenDocument(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if m.CFormat != tt.cFormat {
+				t.Errorf("Document.CFormat = %v, want %v", m.CFormat, tt.cFormat)
+			}
+			if got := m.GetLine(1); got != "compressed line2" {
+				t.Errorf("Document.GetLine(1) = %v, want %v", got, "compressed line2")
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -79,6 +80,92 @@ func NewSearcher(word string, searchReg *regexp.Regexp, caseSensitive bool, rege
 	}
 }
 
+// wildcardPattern returns the regular expression of the literal string with the wildcards.
+// * matches any string and ? matches any character.
+func wildcardPattern(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// hasUpper returns true if the string contains uppercase letters.
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchCaseSensitive returns whether to search the word case-sensitively.
+// In smart-case, the word containing uppercase is case-sensitive.
+func (root *Root) searchCaseSensitive(word string, caseSensitive bool) bool {
+	if caseSensitive {
+		return true
+	}
+	return root.Config.SmartCase && hasUpper(word)
+}
+
+// searchPattern returns the pattern of the word according to the search options
+// and whether the pattern is a regular expression.
+func (root *Root) searchPattern(word string) (string, bool) {
+	pattern, isRegexp := word, root.Config.RegexpSearch
+	if root.Config.WildcardSearch {
+		pattern, isRegexp = wildcardPattern(word), true
+	}
+	if root.Config.WholeWord {
+		if !isRegexp {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		pattern, isRegexp = `\b(?:`+pattern+`)\b`, true
+	}
+	// ^ and $ match at the beginning and end of each line in the multi-line search.
+	if root.Config.MultilineSearch {
+		if !isRegexp {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		pattern, isRegexp = "(?m)"+pattern, true
+	}
+	return pattern, isRegexp
+}
+
+// searchOptions returns the string representing the search options.
+func (root *Root) searchOptions(word string) string {
+	s := ""
+	if _, isRegexp := root.searchPattern(word); isRegexp {
+		s += "r"
+	}
+	if root.searchCaseSensitive(word, root.CaseSensitive) {
+		s += "i"
+	}
+	if root.Config.WholeWord {
+		s += "w"
+	}
+	if root.Config.WildcardSearch {
+		s += "g"
+	}
+	if root.Config.MultilineSearch {
+		s += fmt.Sprintf("m%d", root.multilineWindow())
+	}
+	if root.Config.FuzzySearch {
+		s += "f"
+	}
+	if root.columnSearch() {
+		s += fmt.Sprintf("c%s%d", root.Doc.ColumnDelimiter, root.Doc.columnNum)
+	}
+	return s
+}
+
 // regexpCompile is regexp.Compile the search string.
 func regexpCompile(r string, caseSensitive bool) *regexp.Regexp {
 	if !caseSensitive {
@@ -154,25 +241,34 @@ func (root *Root) searchPosition(lN int, lineStr string) [][]int {
 	}
 
 	var poss [][]int
-	if root.Config.RegexpSearch {
-		poss = searchPositionReg(lineStr, root.searchReg)
+	pattern, isHex := parseHexPattern(root.searchWord)
+	if isHex && m.hexMode() {
+		poss = m.hexSearchPosition(lN, pattern)
+	} else if root.Config.FuzzySearch {
+		poss = root.fuzzyPosition(lineStr)
+	} else if root.Config.MultilineSearch {
+		poss = root.multilinePosition(lN)
+	} else if root.columnSearch() {
+		poss = root.columnPosition(lineStr, root.linePosition)
 	} else {
-		poss = searchPositionStr(root.Config.CaseSensitive, lineStr, root.searchWord)
+		poss = root.linePosition(lineStr)
 	}
 
 	m.cache.Set(key, poss, 3)
 	return poss
 }
 
+// linePosition returns the positions of the search in lineStr.
+func (root *Root) linePosition(lineStr string) [][]int {
+	if _, isRegexp := root.searchPattern(root.searchWord); isRegexp {
+		return searchPositionReg(lineStr, root.searchReg)
+	}
+	return searchPositionStr(root.searchCaseSensitive(root.searchWord, root.CaseSensitive), lineStr, root.searchWord)
+}
+
 // searcKey returns a search key for the cache.
 func (root *Root) searchKey(lN int) string {
-	s := "s"
-	if root.Config.RegexpSearch {
-		s += "r"
-	}
-	if root.Config.CaseSensitive {
-		s += "i"
-	}
+	s := "s" + root.searchOptions(root.searchWord)
 	return fmt.Sprintf("search:%d:%s:%s", lN, s, root.searchWord)
 }
 
@@ -212,13 +308,35 @@ func (root *Root) setSearcher(word string, caseSensitive bool) Searcher {
 	if word == "" {
 		root.searchWord = ""
 		root.searchReg = nil
+		root.countMatch()
 		return nil
 	}
 	root.input.value = word
 	root.searchWord = word
-	root.searchReg = regexpCompile(root.searchWord, caseSensitive)
+	pattern, _ := root.searchPattern(word)
+	root.searchReg = regexpCompile(pattern, root.searchCaseSensitive(word, caseSensitive))
+	root.countMatch()
+
+	// In hex mode, a hex string is searched as a byte pattern.
+	if pattern, ok := parseHexPattern(word); ok && root.Doc.hexMode() {
+		return hexWord{pattern: pattern}
+	}
+	return root.searcher(caseSensitive)
+}
 
-	return NewSearcher(root.searchWord, root.searchReg, caseSensitive, root.Config.RegexpSearch)
+// searcher returns the Searcher of the search word according to the search options.
+func (root *Root) searcher(caseSensitive bool) Searcher {
+	if root.Config.FuzzySearch {
+		return newFuzzyWord(root.searchWord, root.searchCaseSensitive(root.searchWord, caseSensitive))
+	}
+	if root.Config.MultilineSearch && root.searchReg != nil {
+		return multilineWord{
+			word:   root.searchReg,
+			window: root.multilineWindow(),
+		}
+	}
+	pattern, isRegexp := root.searchPattern(root.searchWord)
+	return root.columnSearcher(NewSearcher(pattern, root.searchReg, root.searchCaseSensitive(root.searchWord, caseSensitive), isRegexp))
 }
 
 // searchMove searches forward/backward and moves to the nearest matching line.
@@ -226,6 +344,11 @@ func (root *Root) searchMove(ctx context.Context, forward bool, lN int, searcher
 	if searcher == nil {
 		return
 	}
+	// The fuzzy search moves in order of the score.
+	if w, ok := searcher.(fuzzyWord); ok {
+		root.fuzzyMove(ctx, forward, w)
+		return
+	}
 	root.setMessagef("search:%v (%v)Cancel", root.searchWord, strings.Join(root.cancelKeys, ","))
 	eg, ctx := errgroup.WithContext(ctx)
 	ctx, cancel := context.WithCancel(ctx)
@@ -236,6 +359,7 @@ func (root *Root) searchMove(ctx context.Context, forward bool, lN int, searcher
 		return root.cancelWait()
 	})
 
+	docNum := root.CurrentDoc
 	eg.Go(func() error {
 		var err error
 		if forward {
@@ -243,21 +367,168 @@ func (root *Root) searchMove(ctx context.Context, forward bool, lN int, searcher
 		} else {
 			lN, err = root.Doc.BackSearchLine(ctx, searcher, lN)
 		}
-		root.searchQuit()
-		if err != nil {
-			return err
+		// After searching all documents, the search continues into the other documents.
+		if errors.Is(err, ErrNotFound) && root.searchAll {
+			docNum, lN, err = root.searchOtherDocs(ctx, searcher, forward)
 		}
-		root.moveLine(lN - root.Doc.firstLine())
-		return nil
+		root.searchQuit()
+		return err
 	})
 
 	if err := eg.Wait(); err != nil {
 		root.setMessage(err.Error())
 		return
 	}
+	if docNum != root.CurrentDoc {
+		root.switchDocument(docNum)
+	}
+	root.moveLine(lN - root.Doc.firstLine())
 	root.setMessagef("search:%v", root.searchWord)
 }
 
+// searchOtherDocs searches the documents following the current document in order
+// and returns the document number and the line number of the first match.
+// The backward search searches the preceding documents from the end.
+func (root *Root) searchOtherDocs(ctx context.Context, searcher Searcher, forward bool) (int, int, error) {
+	root.mu.RLock()
+	docs := append([]*Document(nil), root.DocList...)
+	current := root.CurrentDoc
+	root.mu.RUnlock()
+
+	for i := 1; i < len(docs); i++ {
+		var n, lN int
+		var err error
+		if forward {
+			n = (current + i) % len(docs)
+			lN, err = docs[n].SearchLine(ctx, searcher, docs[n].firstLine())
+		} else {
+			n = (current - i + len(docs)) % len(docs)
+			lN, err = docs[n].BackSearchLine(ctx, searcher, docs[n].viewEndNum()-1)
+		}
+		if err == nil {
+			return n, lN, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return 0, 0, err
+		}
+	}
+	return 0, 0, ErrNotFound
+}
+
+// matchCount represents the matching lines of a document.
+type matchCount struct {
+	// count is the number of matching lines.
+	count int
+	// first is the first matching line, or -1 if there is no match.
+	first int
+	// next is the first matching line after the start position, or -1 if there is no match.
+	next int
+}
+
+// countMatch counts the lines displayed on the screen that match searcher.
+func (m *Document) countMatch(ctx context.Context, searcher Searcher, start int) (matchCount, error) {
+	c := matchCount{first: -1, next: -1}
+	for n := 0; n < m.viewEndNum(); n++ {
+		if m.matchView(searcher, n) {
+			c.count++
+			if c.first < 0 {
+				c.first = n
+			}
+			if c.next < 0 && n >= start {
+				c.next = n
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return c, ErrCancel
+		default:
+		}
+	}
+	return c, nil
+}
+
+// searchAllDocs searches all documents concurrently
+// and moves to the next match from the current position.
+// The number of matches in each document is displayed,
+// and the subsequent search continues into the other documents.
+func (root *Root) searchAllDocs(ctx context.Context, searcher Searcher) {
+	if searcher == nil {
+		return
+	}
+	root.searchAll = true
+	root.setMessagef("search all:%v (%v)Cancel", root.searchWord, strings.Join(root.cancelKeys, ","))
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	root.cancelFunc = cancel
+
+	eg.Go(func() error {
+		return root.cancelWait()
+	})
+
+	root.mu.RLock()
+	docs := append([]*Document(nil), root.DocList...)
+	current := root.CurrentDoc
+	root.mu.RUnlock()
+	counts := make([]matchCount, len(docs))
+	eg.Go(func() error {
+		defer root.searchQuit()
+		dg, ctx := errgroup.WithContext(ctx)
+		for n, doc := range docs {
+			n, doc := n, doc
+			start := doc.firstLine()
+			if n == current {
+				start = doc.topLN + doc.firstLine() + 1
+			}
+			dg.Go(func() error {
+				c, err := doc.countMatch(ctx, searcher, start)
+				counts[n] = c
+				return err
+			})
+		}
+		return dg.Wait()
+	})
+
+	if err := eg.Wait(); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+
+	total := 0
+	hits := make([]string, 0, len(docs))
+	for n, c := range counts {
+		total += c.count
+		hits = append(hits, fmt.Sprintf("[%d]%d", n, c.count))
+	}
+	message := fmt.Sprintf("search all:%v %d (%s)", root.searchWord, total, strings.Join(hits, " "))
+	if total == 0 {
+		root.setMessage(message)
+		return
+	}
+
+	docNum, lN := nextMatchDoc(counts, current)
+	if docNum != root.CurrentDoc {
+		root.switchDocument(docNum)
+	}
+	root.moveLine(lN - root.Doc.firstLine())
+	root.setMessage(message)
+}
+
+// nextMatchDoc returns the document number and the line number of the next match
+// from the current position of the current document.
+func nextMatchDoc(counts []matchCount, current int) (int, int) {
+	if counts[current].next >= 0 {
+		return current, counts[current].next
+	}
+	for i := 1; i <= len(counts); i++ {
+		n := (current + i) % len(counts)
+		if counts[n].first >= 0 {
+			return n, counts[n].first
+		}
+	}
+	return current, 0
+}
+
 // incSearch implements incremental forward/back search.
 func (root *Root) incSearch(ctx context.Context, forward bool) {
 	root.Doc.topLN = root.returnStartPosition()
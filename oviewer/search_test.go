@@ -1,6 +1,7 @@
 package oviewer
 
 import (
+	"context"
 	"reflect"
 	"regexp"
 	"strings"
@@ -578,3 +579,205 @@ func TestRoot_setSearch(t *testing.T) {
 		})
 	}
 }
+
+func testSearchDocs(t *testing.T, strs ...string) []*Document {
+	t.Helper()
+	docs := make([]*Document, 0, len(strs))
+	for _, str := range strs {
+		m, err := NewDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ReadAll(strings.NewReader(str)); err != nil {
+			t.Fatal(err)
+		}
+		<-m.eofCh
+		docs = append(docs, m)
+	}
+	return docs
+}
+
+func TestDocument_countMatch(t *testing.T) {
+	m := testSearchDocs(t, "a\ntest1\nb\ntest2\ntest3\n")[0]
+	tests := []struct {
+		name  string
+		start int
+		want  matchCount
+	}{
+		{
+			name:  "testStart",
+			start: 0,
+			want:  matchCount{count: 3, first: 1, next: 1},
+		},
+		{
+			name:  "testMiddle",
+			start: 2,
+			want:  matchCount{count: 3, first: 1, next: 3},
+		},
+		{
+			name:  "testEnd",
+			start: 5,
+			want:  matchCount{count: 3, first: 1, next: -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.countMatch(context.Background(), searchWord{word: "test"}, tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Document.countMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextMatchDoc(t *testing.T) {
+	counts := []matchCount{
+		{count: 1, first: 3, next: -1},
+		{count: 0, first: -1, next: -1},
+		{count: 2, first: 5, next: 5},
+	}
+	tests := []struct {
+		name     string
+		current  int
+		wantDoc  int
+		wantLine int
+	}{
+		{
+			name:     "testNextDoc",
+			current:  0,
+			wantDoc:  2,
+			wantLine: 5,
+		},
+		{
+			name:     "testCurrent",
+			current:  2,
+			wantDoc:  2,
+			wantLine: 5,
+		},
+		{
+			name:     "testWrap",
+			current:  1,
+			wantDoc:  2,
+			wantLine: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDoc, gotLine := nextMatchDoc(counts, tt.current)
+			if gotDoc != tt.wantDoc || gotLine != tt.wantLine {
+				t.Errorf("nextMatchDoc() = %v, %v, want %v, %v", gotDoc, gotLine, tt.wantDoc, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestRoot_searchOtherDocs(t *testing.T) {
+	root := &Root{
+		DocList: testSearchDocs(t, "test0\n", "a\nb\n", "a\ntest2\ntest2\n"),
+	}
+	tests := []struct {
+		name     string
+		current  int
+		forward  bool
+		wantDoc  int
+		wantLine int
+		wantErr  bool
+	}{
+		{
+			name:     "testForward",
+			current:  0,
+			forward:  true,
+			wantDoc:  2,
+			wantLine: 1,
+		},
+		{
+			name:     "testForwardWrap",
+			current:  2,
+			forward:  true,
+			wantDoc:  0,
+			wantLine: 0,
+		},
+		{
+			name:     "testBackward",
+			current:  0,
+			forward:  false,
+			wantDoc:  2,
+			wantLine: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.CurrentDoc = tt.current
+			gotDoc, gotLine, err := root.searchOtherDocs(context.Background(), searchWord{word: "test"}, tt.forward)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Root.searchOtherDocs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotDoc != tt.wantDoc || gotLine != tt.wantLine {
+				t.Errorf("Root.searchOtherDocs() = %v, %v, want %v, %v", gotDoc, gotLine, tt.wantDoc, tt.wantLine)
+			}
+		})
+	}
+}
+
+func Test_wildcardPattern(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{name: "testNoWildcard", word: "a.b", want: `a\.b`},
+		{name: "testStar", word: "err*.log", want: `err.*\.log`},
+		{name: "testQuestion", word: "v?.0", want: `v.\.0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wildcardPattern(tt.word); got != tt.want {
+				t.Errorf("wildcardPattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_setSearcherOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		word   string
+		str    string
+		want   bool
+	}{
+		{name: "testSmartCaseLower", config: Config{SmartCase: true}, word: "error", str: "ERROR", want: true},
+		{name: "testSmartCaseUpper", config: Config{SmartCase: true}, word: "Error", str: "error", want: false},
+		{name: "testSmartCaseUpperMatch", config: Config{SmartCase: true}, word: "Error", str: "Error", want: true},
+		{name: "testWholeWord", config: Config{WholeWord: true}, word: "err", str: "an err here", want: true},
+		{name: "testWholeWordPart", config: Config{WholeWord: true}, word: "err", str: "error", want: false},
+		{name: "testWholeWordLiteral", config: Config{WholeWord: true}, word: "a.b", str: "axb", want: false},
+		{name: "testWildcard", config: Config{WildcardSearch: true}, word: "req*done", str: "request 1 done", want: true},
+		{name: "testWildcardLiteral", config: Config{WildcardSearch: true, RegexpSearch: true}, word: "a.b", str: "axb", want: false},
+		{name: "testWildcardQuestion", config: Config{WildcardSearch: true}, word: "v?.0", str: "v1.0", want: true},
+		{name: "testWildcardWholeWord", config: Config{WildcardSearch: true, WholeWord: true}, word: "re*t", str: "requests", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			root := &Root{
+				Doc:    m,
+				input:  &Input{},
+				Config: tt.config,
+			}
+			searcher := root.setSearcher(tt.word, root.CaseSensitive)
+			if got := searcher.Match(tt.str); got != tt.want {
+				t.Errorf("Searcher.Match(%q) = %v, want %v", tt.str, got, tt.want)
+			}
+			if got := len(root.searchPosition(0, tt.str)) > 0; got != tt.want {
+				t.Errorf("Root.searchPosition(%q) = %v, want %v", tt.str, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package oviewer
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// searchChunkLines is the number of lines searched by one goroutine.
+// It is a multiple of chunkLines so that a goroutine reads the same chunks of the line index.
+var searchChunkLines = chunkLines * 4
+
+// searchWorkers is the number of chunks searched in parallel.
+var searchWorkers = runtime.GOMAXPROCS(0)
+
+// searchParallel searches the lines from num in the direction and returns the nearest matching line.
+// The lines are split into chunks that are searched in parallel,
+// searchWorkers chunks at a time, so that the search ends with the chunks containing the nearest match.
+// The lines added while searching forward (as in follow mode) are also searched.
+func (m *Document) searchParallel(ctx context.Context, searcher Searcher, num int, forward bool) (int, error) {
+	for {
+		var ranges [][2]int
+		if forward {
+			endNum := m.BufEndNum()
+			for start := num; start < endNum && len(ranges) < searchWorkers; start += searchChunkLines {
+				ranges = append(ranges, [2]int{start, min(start+searchChunkLines, endNum)})
+			}
+		} else {
+			for start := num; start >= 0 && len(ranges) < searchWorkers; start -= searchChunkLines {
+				ranges = append(ranges, [2]int{start, max(start-searchChunkLines, -1)})
+			}
+		}
+		if len(ranges) == 0 {
+			return 0, ErrNotFound
+		}
+
+		n, err := m.searchChunks(ctx, searcher, ranges)
+		if err != nil {
+			return 0, err
+		}
+		if n >= 0 {
+			return n, nil
+		}
+		num = ranges[len(ranges)-1][1]
+	}
+}
+
+// searchView searches the lines displayed on the screen from num in the direction
+// while they are not the lines of the document.
+func (m *Document) searchView(ctx context.Context, searcher Searcher, num int, forward bool) (int, error) {
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for n := num; n >= 0 && n < m.viewEndNum(); n += step {
+		if n%countBlock == 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ErrCancel
+			default:
+			}
+		}
+		if searcher.Match(m.viewLine(n)) {
+			return n, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// searchChunks searches the ranges in parallel and returns the matching line of the first range that matches.
+// Returns -1 if no range matches.
+// A range is [start, end) forward, or (end, start] backward if start > end.
+func (m *Document) searchChunks(ctx context.Context, searcher Searcher, ranges [][2]int) (int, error) {
+	results := make([]int, len(ranges))
+	// nearest is the index of the nearest range found so far.
+	// The farther ranges stop searching.
+	nearest := int32(len(ranges))
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, r := range ranges {
+		i, r := i, r
+		results[i] = -1
+		eg.Go(func() error {
+			step := 1
+			if r[0] > r[1] {
+				step = -1
+			}
+			for n := r[0]; n != r[1]; n += step {
+				if atomic.LoadInt32(&nearest) < int32(i) {
+					return nil
+				}
+				if m.matchLine(searcher, n) {
+					results[i] = n
+					for {
+						old := atomic.LoadInt32(&nearest)
+						if old <= int32(i) || atomic.CompareAndSwapInt32(&nearest, old, int32(i)) {
+							break
+						}
+					}
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return ErrCancel
+				default:
+				}
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return 0, err
+	}
+	for _, n := range results {
+		if n >= 0 {
+			return n, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,77 @@
+package oviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDocument_searchParallel(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 100; i++ {
+		if i%30 == 7 {
+			fmt.Fprintf(&b, "\x1b[31mmatch %d\x1b[0m\n", i)
+			continue
+		}
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(b.String())); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+
+	chunkLines, workers := searchChunkLines, searchWorkers
+	defer func() {
+		searchChunkLines, searchWorkers = chunkLines, workers
+	}()
+	searchChunkLines, searchWorkers = 4, 3
+
+	tests := []struct {
+		name    string
+		num     int
+		forward bool
+		want    int
+		wantErr error
+	}{
+		{name: "testForward", num: 0, forward: true, want: 7},
+		{name: "testForwardSame", num: 37, forward: true, want: 37},
+		{name: "testForwardNextRound", num: 38, forward: true, want: 67},
+		{name: "testForwardNotFound", num: 98, forward: true, wantErr: ErrNotFound},
+		{name: "testBackward", num: 99, forward: false, want: 97},
+		{name: "testBackwardNextRound", num: 66, forward: false, want: 37},
+		{name: "testBackwardNotFound", num: 6, forward: false, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.searchParallel(context.Background(), searchWord{word: "match"}, tt.num, tt.forward)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Document.searchParallel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Document.searchParallel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_searchParallelCancel(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(strings.Repeat("line\n", 1000))); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.searchParallel(ctx, searchWord{word: "match"}, 0, true); !errors.Is(err, ErrCancel) {
+		t.Errorf("Document.searchParallel() error = %v, want %v", err, ErrCancel)
+	}
+}
@@ -0,0 +1,553 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// checkpointSpan is the minimum number of uncompressed bytes between checkpoints.
+const checkpointSpan = 1 << 20
+
+// checkpoint is a position where decompression can be restarted.
+type checkpoint struct {
+	// uoff is the offset in the uncompressed data.
+	uoff int64
+	// coff is the offset in the compressed file.
+	coff int64
+	// bit is the number of bits of the byte at coff that have been used (gzip).
+	bit uint
+	// window is the uncompressed data before uoff that deflate refers to (gzip).
+	window []byte
+	// block is the number of the block (xz).
+	block int
+}
+
+// seekFormat returns true if the compressed format can be read
+// from the middle by checkpoints.
+func seekFormat(cFormat Compressed) bool {
+	switch cFormat {
+	case GZIP, ZSTD, XZ:
+		return true
+	}
+	return false
+}
+
+// seekCursors is the maximum number of readers kept to continue from the last ReadAt.
+const seekCursors = 8
+
+// seekReader is an io.ReaderAt of the uncompressed data of a compressed file.
+// Decompression starts from the nearest checkpoint before the offset,
+// so that reading the middle of the file does not decompress from the beginning.
+// Checkpoints are recorded during the first read (zstd),
+// while decompressing from a checkpoint (gzip),
+// or read from the index of the file (xz).
+// ReadAt can be called in parallel, and each call decompresses with its own reader.
+type seekReader struct {
+	file    *os.File
+	cFormat Compressed
+
+	mu sync.Mutex
+	// points is a list of checkpoints in ascending order of uoff.
+	points []checkpoint
+	// xzBlocks is the list of xz blocks.
+	xzBlocks []xzBlock
+	// cursors are the readers that continue from the last ReadAt.
+	cursors []*seekCursor
+	// gen is incremented by reset to discard the readers in use.
+	gen int
+}
+
+// seekCursor is a reader that continues from the offset.
+type seekCursor struct {
+	r   io.Reader
+	off int64
+	gen int
+}
+
+// newSeekReader returns a seekReader of the compressed file.
+func newSeekReader(file *os.File, cFormat Compressed) *seekReader {
+	s := &seekReader{
+		file:    file,
+		cFormat: cFormat,
+	}
+	s.reset()
+	if cFormat == XZ {
+		blocks, err := readXZIndex(file)
+		if err != nil {
+			// Decompress from the beginning.
+			return s
+		}
+		s.xzBlocks = blocks
+		for i, b := range blocks {
+			if i > 0 {
+				s.points = append(s.points, checkpoint{uoff: b.uoff, coff: b.coff, block: i})
+			}
+		}
+	}
+	return s
+}
+
+// reset clears the checkpoints.
+func (s *seekReader) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.points = []checkpoint{{}}
+	s.cursors = nil
+	s.gen++
+}
+
+// adder returns a function that adds a checkpoint,
+// which does nothing after reset.
+func (s *seekReader) adder() func(checkpoint) {
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+	return func(cp checkpoint) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if gen != s.gen || cp.uoff <= s.points[len(s.points)-1].uoff {
+			return
+		}
+		s.points = append(s.points, cp)
+	}
+}
+
+// record returns a reader of the first read.
+// r must be positioned at the beginning of the file.
+// The checkpoints of zstd are recorded at the beginning of the frames.
+// gzip is read with compress/gzip, and the checkpoints are recorded by ReadAt.
+func (s *seekReader) record(r io.Reader) io.Reader {
+	if s.cFormat == ZSTD {
+		return newZstdFrameReader(r, checkpoint{}, s.adder())
+	}
+	return compressedFormatReader(s.cFormat, r)
+}
+
+// ReadAt reads the uncompressed data at offset off.
+func (s *seekReader) ReadAt(p []byte, off int64) (int, error) {
+	c, err := s.cursor(off)
+	if err != nil {
+		return 0, err
+	}
+
+	if off > c.off {
+		n, err := io.CopyN(io.Discard, c.r, off-c.off)
+		c.off += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.ReadFull(c.r, p)
+	c.off += int64(n)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	if err == nil {
+		s.release(c)
+	}
+	return n, err
+}
+
+// cursor returns the reader closest before off,
+// or a new reader from the nearest checkpoint if it is closer.
+func (s *seekReader) cursor(off int64) (*seekCursor, error) {
+	s.mu.Lock()
+	cp := s.points[sort.Search(len(s.points), func(i int) bool {
+		return s.points[i].uoff > off
+	})-1]
+	found := -1
+	for i, c := range s.cursors {
+		if c.off < cp.uoff || c.off > off {
+			continue
+		}
+		if found < 0 || c.off > s.cursors[found].off {
+			found = i
+		}
+	}
+	if found >= 0 {
+		c := s.cursors[found]
+		s.cursors = append(s.cursors[:found], s.cursors[found+1:]...)
+		s.mu.Unlock()
+		return c, nil
+	}
+	gen := s.gen
+	s.mu.Unlock()
+
+	r, err := s.open(cp)
+	if err != nil {
+		return nil, err
+	}
+	return &seekCursor{r: r, off: cp.uoff, gen: gen}, nil
+}
+
+// release keeps the reader to continue from the next ReadAt.
+func (s *seekReader) release(c *seekCursor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.gen != s.gen {
+		return
+	}
+	if len(s.cursors) >= seekCursors {
+		s.cursors = s.cursors[1:]
+	}
+	s.cursors = append(s.cursors, c)
+}
+
+// open returns a reader that decompresses from the checkpoint.
+// gzip records the checkpoints after the checkpoint.
+func (s *seekReader) open(cp checkpoint) (io.Reader, error) {
+	r := io.NewSectionReader(s.file, cp.coff, math.MaxInt64-cp.coff)
+	if cp.uoff == 0 {
+		switch s.cFormat {
+		case GZIP:
+			return newGzipReader(r, checkpointSpan, s.adder()), nil
+		case ZSTD:
+			return newZstdFrameReader(r, cp, nil), nil
+		}
+		return compressedFormatReader(s.cFormat, r), nil
+	}
+
+	switch s.cFormat {
+	case GZIP:
+		return resumeGzipReader(r, cp, checkpointSpan, s.adder())
+	case ZSTD:
+		return newZstdFrameReader(r, cp, nil), nil
+	case XZ:
+		return s.resumeXZ(cp)
+	}
+	return nil, ErrCorrupt
+}
+
+// zstdFrameReader decompresses zstd frames one by one,
+// so that decompression can be restarted at the beginning of a frame.
+// The zstd seekable format consists of independent frames and a seek table
+// in a skippable frame, which is skipped.
+type zstdFrameReader struct {
+	r *bufio.Reader
+	// coff is the offset of the next byte to read from r.
+	coff int64
+	// uoff is the offset in the uncompressed data.
+	uoff int64
+
+	decoder *zstd.Decoder
+	// frame is the decoder of the current frame.
+	frame io.Reader
+
+	last         int64
+	onCheckpoint func(checkpoint)
+}
+
+// newZstdFrameReader returns a zstdFrameReader that starts at the checkpoint.
+// If onCheckpoint is not nil, it is called at the beginning of frames every checkpointSpan bytes.
+func newZstdFrameReader(r io.Reader, cp checkpoint, onCheckpoint func(checkpoint)) *zstdFrameReader {
+	return &zstdFrameReader{
+		r:            bufio.NewReader(r),
+		coff:         cp.coff,
+		uoff:         cp.uoff,
+		last:         cp.uoff,
+		onCheckpoint: onCheckpoint,
+	}
+}
+
+// Read reads the decompressed data.
+func (z *zstdFrameReader) Read(p []byte) (int, error) {
+	for {
+		if z.frame == nil {
+			if err := z.nextFrame(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := z.frame.Read(p)
+		z.uoff += int64(n)
+		if errors.Is(err, io.EOF) {
+			z.frame = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// nextFrame starts decoding the next frame, skipping skippable frames.
+func (z *zstdFrameReader) nextFrame() error {
+	for {
+		magic, err := z.r.Peek(4)
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(magic) == 0 {
+				return io.EOF
+			}
+			return err
+		}
+
+		m := binary.LittleEndian.Uint32(magic)
+		if m&0xfffffff0 == 0x184d2a50 {
+			// Skippable frame.
+			header := make([]byte, 8)
+			if _, err := io.ReadFull(z.r, header); err != nil {
+				return err
+			}
+			size := int64(binary.LittleEndian.Uint32(header[4:]))
+			if _, err := io.CopyN(io.Discard, z.r, size); err != nil {
+				return err
+			}
+			z.coff += 8 + size
+			continue
+		}
+		if m != 0xfd2fb528 {
+			return ErrInvalidHeader
+		}
+
+		if z.onCheckpoint != nil && z.uoff-z.last >= checkpointSpan {
+			z.onCheckpoint(checkpoint{uoff: z.uoff, coff: z.coff})
+			z.last = z.uoff
+		}
+		frame, err := newZstdFrame(z.r, &z.coff)
+		if err != nil {
+			return err
+		}
+		if z.decoder == nil {
+			z.decoder, err = zstd.NewReader(frame, zstd.WithDecoderConcurrency(1))
+		} else {
+			err = z.decoder.Reset(frame)
+		}
+		if err != nil {
+			return err
+		}
+		z.frame = z.decoder
+		return nil
+	}
+}
+
+// zstdFrame is a reader of the bytes of one zstd frame.
+// The end of the frame is found by the block headers without decoding.
+type zstdFrame struct {
+	r    *bufio.Reader
+	coff *int64
+	// pending is the header bytes to pass.
+	pending []byte
+	// remain is the number of bytes to pass of the current block.
+	remain   int64
+	lastSeen bool
+	checksum bool
+	done     bool
+}
+
+// newZstdFrame reads the frame header and returns a zstdFrame.
+func newZstdFrame(r *bufio.Reader, coff *int64) (*zstdFrame, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	fhd := header[4]
+	size := 0
+	if fhd&0x20 == 0 {
+		// Window descriptor.
+		size++
+	}
+	size += []int{0, 1, 2, 4}[fhd&3]
+	switch fhd >> 6 {
+	case 0:
+		if fhd&0x20 != 0 {
+			size++
+		}
+	case 1:
+		size += 2
+	case 2:
+		size += 4
+	case 3:
+		size += 8
+	}
+	rest := make([]byte, size)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	*coff += int64(len(header) + size)
+	return &zstdFrame{
+		r:        r,
+		coff:     coff,
+		pending:  append(header, rest...),
+		checksum: fhd&0x04 != 0,
+	}, nil
+}
+
+// Read reads the bytes of the frame.
+func (f *zstdFrame) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 && f.remain == 0 {
+		if f.done {
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	if len(f.pending) > 0 {
+		n := copy(p, f.pending)
+		f.pending = f.pending[n:]
+		return n, nil
+	}
+
+	if int64(len(p)) > f.remain {
+		p = p[:f.remain]
+	}
+	n, err := f.r.Read(p)
+	f.remain -= int64(n)
+	*f.coff += int64(n)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// next reads the next block header.
+func (f *zstdFrame) next() error {
+	if f.lastSeen {
+		f.done = true
+		if f.checksum {
+			f.remain = 4
+		}
+		return nil
+	}
+
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(f.r, header); err != nil {
+		return err
+	}
+	*f.coff += 3
+	f.pending = header
+	v := uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16
+	f.lastSeen = v&1 == 1
+	switch (v >> 1) & 3 {
+	case 0, 2:
+		// Raw and compressed blocks.
+		f.remain = int64(v >> 3)
+	case 1:
+		// RLE block.
+		f.remain = 1
+	default:
+		return ErrCorrupt
+	}
+	return nil
+}
+
+// xzBlock represents a block of xz.
+type xzBlock struct {
+	// coff is the offset of the block in the file.
+	coff int64
+	// uoff is the offset in the uncompressed data.
+	uoff int64
+	// unpadded is the unpadded size of the block.
+	unpadded int64
+	// size is the uncompressed size of the block.
+	size int64
+}
+
+// xzHeaderLen is the length of the stream header and the stream footer of xz.
+const xzHeaderLen = 12
+
+// readXZIndex reads the index at the end of the xz file and returns the blocks.
+// Only a single stream without padding is supported.
+func readXZIndex(f *os.File) ([]xzBlock, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	footer := make([]byte, xzHeaderLen)
+	if _, err := f.ReadAt(footer, fi.Size()-xzHeaderLen); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(footer[10:], []byte("YZ")) {
+		return nil, ErrInvalidHeader
+	}
+	indexSize := (int64(binary.LittleEndian.Uint32(footer[4:8])) + 1) * 4
+	indexStart := fi.Size() - xzHeaderLen - indexSize
+	if indexStart < xzHeaderLen {
+		return nil, ErrInvalidHeader
+	}
+	index := make([]byte, indexSize)
+	if _, err := f.ReadAt(index, indexStart); err != nil {
+		return nil, err
+	}
+	if index[0] != 0 || crc32.ChecksumIEEE(index[:indexSize-4]) != binary.LittleEndian.Uint32(index[indexSize-4:]) {
+		return nil, ErrInvalidHeader
+	}
+
+	br := bytes.NewReader(index[1:])
+	num, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]xzBlock, 0, num)
+	coff, uoff := int64(xzHeaderLen), int64(0)
+	for i := uint64(0); i < num; i++ {
+		unpadded, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, xzBlock{coff: coff, uoff: uoff, unpadded: int64(unpadded), size: int64(size)})
+		coff += (int64(unpadded) + 3) &^ 3
+		uoff += int64(size)
+	}
+	// The blocks must fill up to the index.
+	if coff != indexStart {
+		return nil, ErrInvalidHeader
+	}
+	return blocks, nil
+}
+
+// resumeXZ returns a reader that decompresses from the block of the checkpoint.
+// The reader is a stream consisting of the stream header,
+// the blocks from the checkpoint and the index of those blocks.
+func (s *seekReader) resumeXZ(cp checkpoint) (io.Reader, error) {
+	header := make([]byte, xzHeaderLen)
+	if _, err := s.file.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	blocks := s.xzBlocks[cp.block:]
+
+	var index bytes.Buffer
+	index.WriteByte(0)
+	buf := make([]byte, binary.MaxVarintLen64)
+	index.Write(buf[:binary.PutUvarint(buf, uint64(len(blocks)))])
+	for _, b := range blocks {
+		index.Write(buf[:binary.PutUvarint(buf, uint64(b.unpadded))])
+		index.Write(buf[:binary.PutUvarint(buf, uint64(b.size))])
+	}
+	for index.Len()%4 != 0 {
+		index.WriteByte(0)
+	}
+	crc := make([]byte, 4)
+	binary.LittleEndian.PutUint32(crc, crc32.ChecksumIEEE(index.Bytes()))
+	index.Write(crc)
+
+	footer := make([]byte, xzHeaderLen)
+	binary.LittleEndian.PutUint32(footer[4:8], uint32(index.Len()/4-1))
+	copy(footer[8:10], header[6:8])
+	copy(footer[10:], "YZ")
+	binary.LittleEndian.PutUint32(footer[0:4], crc32.ChecksumIEEE(footer[4:10]))
+
+	last := blocks[len(blocks)-1]
+	end := last.coff + (last.unpadded+3)&^3
+	return xz.NewReader(io.MultiReader(
+		bytes.NewReader(header),
+		io.NewSectionReader(s.file, cp.coff, end-cp.coff),
+		&index,
+		bytes.NewReader(footer),
+	))
+}
@@ -0,0 +1,189 @@
+package oviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// testZstdFrames compresses data into independent frames with a skippable frame at the end,
+// like the zstd seekable format.
+func testZstdFrames(t *testing.T, data []byte, frameSize int) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	var buf []byte
+	for start := 0; start < len(data); start += frameSize {
+		end := min(start+frameSize, len(data))
+		buf = enc.EncodeAll(data[start:end], buf)
+	}
+	// Skippable frame of the seek table.
+	buf = append(buf, 0x5e, 0x2a, 0x4d, 0x18, 4, 0, 0, 0, 1, 2, 3, 4)
+	return buf
+}
+
+func testXZBlocks(t *testing.T, data []byte, blockSize int64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := xz.WriterConfig{BlockSize: blockSize}.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testSeekFile(t *testing.T, fileName string, compressed []byte) *os.File {
+	t.Helper()
+	fileName = filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(fileName, compressed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		f.Close()
+	})
+	return f
+}
+
+func Test_seekReader(t *testing.T) {
+	data := testSeekData(4 << 20)
+	tests := []struct {
+		name       string
+		fileName   string
+		compressed []byte
+		cFormat    Compressed
+	}{
+		{
+			name:       "testGzip",
+			fileName:   "test.txt.gz",
+			compressed: testGzip(t, data, gzip.DefaultCompression, 1),
+			cFormat:    GZIP,
+		},
+		{
+			name:       "testZstd",
+			fileName:   "test.txt.zst",
+			compressed: testZstdFrames(t, data, 256<<10),
+			cFormat:    ZSTD,
+		},
+		{
+			name:       "testXZ",
+			fileName:   "test.txt.xz",
+			compressed: testXZBlocks(t, data, 1<<20),
+			cFormat:    XZ,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSeekReader(testSeekFile(t, tt.fileName, tt.compressed), tt.cFormat)
+			if tt.cFormat != XZ {
+				// Record the checkpoints.
+				got, err := io.ReadAll(s.record(bytes.NewReader(tt.compressed)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Fatalf("seekReader.record() read %d bytes, want %d bytes", len(got), len(data))
+				}
+			}
+			// The checkpoints of gzip are recorded by ReadAt.
+			if _, err := s.ReadAt(make([]byte, 1), int64(len(data)-1)); err != nil {
+				t.Fatal(err)
+			}
+			if len(s.points) < 3 {
+				t.Fatalf("seekReader has %d checkpoints, want at least 3", len(s.points))
+			}
+
+			rnd := rand.New(rand.NewSource(3))
+			for i := 0; i < 20; i++ {
+				off := rnd.Int63n(int64(len(data)))
+				p := make([]byte, rnd.Intn(100000)+1)
+				n, err := s.ReadAt(p, off)
+				want := data[off:min(int(off)+len(p), len(data))]
+				if n < len(p) && err != io.EOF {
+					t.Fatalf("seekReader.ReadAt(%d) error = %v", off, err)
+				}
+				if !bytes.Equal(p[:n], want) {
+					t.Fatalf("seekReader.ReadAt(%d) read %q..., want %q...", off, p[:min(n, 20)], want[:min(len(want), 20)])
+				}
+			}
+		})
+	}
+}
+
+func Test_seekReaderParallel(t *testing.T) {
+	data := testSeekData(4 << 20)
+	compressed := testGzip(t, data, gzip.DefaultCompression, 1)
+	s := newSeekReader(testSeekFile(t, "test.txt.gz", compressed), GZIP)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for i := 0; i < 10; i++ {
+				off := rnd.Int63n(int64(len(data)))
+				p := make([]byte, rnd.Intn(10000)+1)
+				n, err := s.ReadAt(p, off)
+				if n < len(p) && err != io.EOF {
+					t.Errorf("seekReader.ReadAt(%d) error = %v", off, err)
+					return
+				}
+				if want := data[off:min(int(off)+len(p), len(data))]; !bytes.Equal(p[:n], want) {
+					t.Errorf("seekReader.ReadAt(%d) read %q..., want %q...", off, p[:min(n, 20)], want[:min(len(want), 20)])
+					return
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+}
+
+func TestDocument_lineIndexCompressed(t *testing.T) {
+	data := testSeekData(3 << 20)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	fileName := filepath.Join(t.TempDir(), "test.txt.gz")
+	if err := os.WriteFile(fileName, testGzip(t, data, gzip.DefaultCompression, 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	t.Cleanup(func() {
+		m.closeIndex()
+	})
+	if m.index == nil || m.index.seek == nil {
+		t.Fatal("the compressed file is not indexed")
+	}
+	if got := m.BufEndNum(); got != len(lines) {
+		t.Fatalf("Document.BufEndNum() = %v, want %v", got, len(lines))
+	}
+	for _, n := range []int{len(lines) - 1, 0, len(lines) / 2, chunkLines + 1} {
+		if got := m.GetLine(n); got != lines[n] {
+			t.Errorf("Document.GetLine(%d) = %v, want %v", n, got, lines[n])
+		}
+	}
+}
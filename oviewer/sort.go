@@ -0,0 +1,336 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// SortType is the type of the comparison of the sort.
+type SortType int
+
+const (
+	// SortString compares the columns as strings.
+	SortString SortType = iota
+	// SortNumeric compares the columns as numbers.
+	SortNumeric
+	// SortSize compares the columns as human-readable sizes such as 1.5K and 20MiB.
+	SortSize
+	// SortTime compares the columns as timestamps.
+	SortTime
+)
+
+// sortTypeNames is the names of SortType in the sort input.
+var sortTypeNames = map[string]SortType{
+	"string":  SortString,
+	"numeric": SortNumeric,
+	"size":    SortSize,
+	"time":    SortTime,
+}
+
+// SortOption is the option of SortDocument.
+type SortOption struct {
+	// Column is the column number of the line to sort by, starting from 0.
+	Column int
+	// Type is the type of the comparison.
+	Type SortType
+	// Desc sorts in descending order.
+	Desc bool
+	// Uniq collapses the lines with the same column into the first line,
+	// and adds the number of the lines as the last column.
+	Uniq bool
+	// Follow sorts the lines read so far without waiting for EOF,
+	// because the followed document does not reach EOF.
+	Follow bool
+}
+
+// parseSortOption parses the sort input such as "numeric desc uniq".
+// The type is one of string (default), numeric, size and time.
+func parseSortOption(s string) (SortOption, error) {
+	var option SortOption
+	for _, w := range strings.Fields(strings.ToLower(s)) {
+		if t, ok := sortTypeNames[w]; ok {
+			option.Type = t
+			continue
+		}
+		switch w {
+		case "asc":
+			option.Desc = false
+		case "desc":
+			option.Desc = true
+		case "uniq":
+			option.Uniq = true
+		default:
+			return option, fmt.Errorf("%w: %s", ErrInvalidSort, w)
+		}
+	}
+	return option, nil
+}
+
+// timeLayouts is the layouts of the timestamps to sort.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.UnixDate,
+	time.ANSIC,
+	time.Stamp,
+}
+
+// parseTime parses the timestamp in one of timeLayouts.
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseSize parses the human-readable size such as "512", "1.5K", "20MiB" and "3 GB".
+// The units are in powers of 1024.
+func parseSize(s string) (float64, bool) {
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || (i == 0 && (s[i] == '-' || s[i] == '+'))) {
+		i++
+	}
+	num, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.ToUpper(strings.TrimSpace(s[i:]))
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "B"), "I")
+	if unit == "" {
+		return num, true
+	}
+	exp := strings.Index("KMGTPE", unit)
+	if len(unit) != 1 || exp < 0 {
+		return 0, false
+	}
+	return num * math.Pow(1024, float64(exp+1)), true
+}
+
+// sortKey is the column of the line to compare.
+type sortKey struct {
+	str string
+	num float64
+	// ok is true if the column is parsed as num.
+	ok bool
+}
+
+// newSortKey returns sortKey of the column.
+func newSortKey(field string, t SortType) sortKey {
+	key := sortKey{str: columnName(field)}
+	switch t {
+	case SortNumeric:
+		if f, err := strconv.ParseFloat(key.str, 64); err == nil {
+			key.num, key.ok = f, true
+		}
+	case SortSize:
+		key.num, key.ok = parseSize(key.str)
+	case SortTime:
+		if tm, ok := parseTime(key.str); ok {
+			key.num, key.ok = float64(tm.UnixNano()), true
+		}
+	}
+	return key
+}
+
+// compareSortKey compares the keys that are both parsed or both not parsed.
+func compareSortKey(a, b sortKey) int {
+	if a.ok && b.ok {
+		switch {
+		case a.num < b.num:
+			return -1
+		case a.num > b.num:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+// sortRow is the line of the parent document to sort.
+type sortRow struct {
+	lN   int
+	line string
+	key  sortKey
+}
+
+// SortDocument returns a Document that contains the lines of m sorted by the column.
+// The columns are split with the current settings of m.
+// The header lines of m are contained on top without sorting.
+// The lines are sorted after m is read to the end,
+// or the lines read so far are sorted if option.Follow is true.
+func (m *Document) SortDocument(ctx context.Context, option SortOption) (*Document, error) {
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = m.FileName + ":sort"
+	doc.filter = &filter{
+		parent: m,
+		header: m.firstLine(),
+	}
+	doc.seekable = false
+	doc.preventReload = true
+	ctx, cancel := context.WithCancel(ctx)
+	doc.cancel = cancel
+	go doc.sortLines(ctx, option, m.columnWord(nil, option.Column), m.Header > 0)
+	return doc, nil
+}
+
+// sortLines adds the lines of the parent document sorted by the column.
+// If title is true, the last header line has the title of the count column.
+func (m *Document) sortLines(ctx context.Context, option SortOption, column columnWord, title bool) {
+	defer func() {
+		atomic.StoreInt32(&m.eof, 1)
+		atomic.StoreInt32(&m.changed, 1)
+	}()
+	parent := m.filter.parent
+	for !parent.BufEOF() && !option.Follow {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(filterInterval):
+		}
+	}
+
+	endNum := parent.BufEndNum()
+	header := min(m.filter.header, endNum)
+	rows := make([]sortRow, 0, endNum-header)
+	for n := header; n < endNum; n++ {
+		line := parent.GetLine(n)
+		field, _ := column.field(stripEscapeSequence(line))
+		rows = append(rows, sortRow{lN: n, line: line, key: newSortKey(field, option.Type)})
+		if n%countBlock == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].key, rows[j].key
+		// The columns that are not parsed are last.
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if option.Desc {
+			return compareSortKey(a, b) > 0
+		}
+		return compareSortKey(a, b) < 0
+	})
+
+	nums := make([]int, 0, endNum)
+	lines := make([]string, 0, endNum)
+	for n := 0; n < header; n++ {
+		nums = append(nums, n)
+		lines = append(lines, parent.GetLine(n))
+	}
+	if !option.Uniq {
+		for _, r := range rows {
+			nums = append(nums, r.lN)
+			lines = append(lines, r.line)
+		}
+		m.appendFilter(nums, lines)
+		return
+	}
+
+	var counts []int
+	for i, r := range rows {
+		if i > 0 && r.key.ok == rows[i-1].key.ok && compareSortKey(r.key, rows[i-1].key) == 0 {
+			counts[len(counts)-1]++
+			continue
+		}
+		nums = append(nums, r.lN)
+		lines = append(lines, r.line)
+		counts = append(counts, 1)
+	}
+	// The last header line is the title of the count column.
+	if title && header > 0 {
+		counts = append([]int{-1}, counts...)
+		header--
+	}
+	appendCount(lines[header:], counts, column)
+	m.appendFilter(nums, lines)
+}
+
+// appendCount appends the counts to the lines as the last column.
+// The negative count is the title of the column.
+func appendCount(lines []string, counts []int, column columnWord) {
+	sep := column.delimiter
+	width := 0
+	if column.bounds != nil {
+		// Align the count column for the column width mode.
+		sep = "  "
+		for _, line := range lines {
+			width = max(width, runewidth.StringWidth(stripEscapeSequence(line)))
+		}
+	}
+	for i, count := range counts {
+		c := "count"
+		if count >= 0 {
+			c = strconv.Itoa(count)
+		}
+		pad := ""
+		if column.bounds != nil {
+			pad = strings.Repeat(" ", width-runewidth.StringWidth(stripEscapeSequence(lines[i])))
+		}
+		lines[i] = lines[i] + pad + sep + c
+	}
+}
+
+// sortColumn adds a document sorted by the highlighted column.
+func (root *Root) sortColumn(ctx context.Context, input string) {
+	m := root.Doc
+	if !m.ColumnMode || (m.ColumnDelimiter == "" && !m.ColumnWidth) {
+		root.setMessage("Set ColumnMode to sort by the column")
+		return
+	}
+	option, err := parseSortOption(input)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	option.Column = m.originalColumn(m.columnNum)
+	option.Follow = root.General.FollowAll || m.FollowMode || m.FollowSection
+
+	doc, err := m.SortDocument(ctx, option)
+	if err != nil {
+		root.setMessagef("cannot sort: %s", err)
+		return
+	}
+	doc.FileName = fmt.Sprintf("%s:sort:%d:%s", m.FileName, option.Column+1, input)
+	root.addDocument(doc)
+	if option.Follow {
+		root.setMessage("Sort the lines read so far")
+	}
+
+	// The sorted document is displayed in the same way.
+	doc.general = m.general
+	doc.columnNum = m.columnNum
+	doc.layoutKey = m.layoutKey
+	if m.layout != nil {
+		layout := append([]int{}, m.layout...)
+		if option.Uniq {
+			layout = append(layout, m.columnCount())
+		}
+		doc.setLayout(layout)
+	}
+}
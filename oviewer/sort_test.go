@@ -0,0 +1,230 @@
+package oviewer
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_parseSortOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    SortOption
+		wantErr bool
+	}{
+		{name: "testEmpty", s: "", want: SortOption{}},
+		{name: "testNumericDesc", s: "numeric desc", want: SortOption{Type: SortNumeric, Desc: true}},
+		{name: "testSizeUniq", s: "Size uniq", want: SortOption{Type: SortSize, Uniq: true}},
+		{name: "testTimeAsc", s: "desc time asc", want: SortOption{Type: SortTime}},
+		{name: "testInvalid", s: "random", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSortOption(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSortOption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseSortOption() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSize(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   float64
+		wantOK bool
+	}{
+		{s: "512", want: 512, wantOK: true},
+		{s: "1.5K", want: 1536, wantOK: true},
+		{s: "20MiB", want: 20 * 1024 * 1024, wantOK: true},
+		{s: "3 GB", want: 3 * 1024 * 1024 * 1024, wantOK: true},
+		{s: "10B", want: 10, wantOK: true},
+		{s: "1X", wantOK: false},
+		{s: "K", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseSize(tt.s)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("parseSize() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_newSortKey(t *testing.T) {
+	a := newSortKey("2022-05-01T10:00:00Z", SortTime)
+	b := newSortKey(" 2022/04/30 23:00:00 ", SortTime)
+	if !a.ok || !b.ok {
+		t.Fatalf("newSortKey() ok = %v, %v, want true, true", a.ok, b.ok)
+	}
+	if compareSortKey(a, b) <= 0 {
+		t.Errorf("compareSortKey() = %d, want > 0", compareSortKey(a, b))
+	}
+	if key := newSortKey(`"10"`, SortNumeric); !key.ok || key.num != 10 {
+		t.Errorf("newSortKey() = %v, want 10", key)
+	}
+}
+
+func sortTestDocument(t *testing.T, str string, header int, option SortOption) *Document {
+	t.Helper()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader(str)); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	m.Header = header
+	m.ColumnDelimiter = ","
+	doc, err := m.SortDocument(context.Background(), option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !doc.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return doc
+}
+
+func docLines(m *Document) []string {
+	lines := make([]string, 0, m.BufEndNum())
+	for n := 0; n < m.BufEndNum(); n++ {
+		lines = append(lines, m.GetLine(n))
+	}
+	return lines
+}
+
+func TestDocument_SortDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		header int
+		option SortOption
+		want   []string
+	}{
+		{
+			name:   "testString",
+			str:    "name,size\nbob,1K\nalice,2M\ncarol,512\n",
+			header: 1,
+			option: SortOption{Column: 0},
+			want:   []string{"name,size", "alice,2M", "bob,1K", "carol,512"},
+		},
+		{
+			name:   "testNumericDesc",
+			str:    "a,10\nb,9\nc,-\nd,100\n",
+			option: SortOption{Column: 1, Type: SortNumeric, Desc: true},
+			want:   []string{"d,100", "a,10", "b,9", "c,-"},
+		},
+		{
+			name:   "testSize",
+			str:    "name,size\nbob,1K\nalice,2M\ncarol,512\n",
+			header: 1,
+			option: SortOption{Column: 1, Type: SortSize},
+			want:   []string{"name,size", "carol,512", "bob,1K", "alice,2M"},
+		},
+		{
+			name:   "testUniq",
+			str:    "id,status\n1,ok\n2,error\n3,ok\n4,ok\n",
+			header: 1,
+			option: SortOption{Column: 1, Uniq: true},
+			want:   []string{"id,status,count", "2,error,1", "1,ok,3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := sortTestDocument(t, tt.str, tt.header, tt.option)
+			if got := docLines(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.SortDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_SortDocumentOrigin(t *testing.T) {
+	doc := sortTestDocument(t, "b,1\nc,2\na,3\n", 0, SortOption{})
+	for n, want := range []int{2, 0, 1} {
+		if got, ok := doc.originLine(n); !ok || got != want {
+			t.Errorf("Document.originLine(%d) = %d, %v, want %d", n, got, ok, want)
+		}
+	}
+}
+
+func TestDocument_SortDocumentFollow(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader("b,1\nc,2\na,3\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	// The eof flag is cleared in follow mode.
+	atomic.StoreInt32(&m.openFollow, 1)
+	atomic.StoreInt32(&m.eof, 0)
+	m.ColumnDelimiter = ","
+	doc, err := m.SortDocument(context.Background(), SortOption{Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !doc.BufEOF() {
+		if time.Now().After(deadline) {
+			t.Fatal("Document.SortDocument() does not sort the followed document")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []string{"a,3", "b,1", "c,2"}
+	if got := docLines(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.SortDocument() = %v, want %v", got, want)
+	}
+}
+
+func TestDocument_SortDocumentPipe(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, w := io.Pipe()
+	if err := m.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "b,1\nc,2\n"); err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnDelimiter = ","
+	doc, err := m.SortDocument(context.Background(), SortOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lines are sorted after the pipe is read to the end.
+	time.Sleep(filterInterval * 2)
+	if doc.BufEOF() {
+		t.Fatal("Document.SortDocument() sorts before EOF")
+	}
+	if _, err := io.WriteString(w, "a,3\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	<-m.eofCh
+	deadline := time.Now().Add(5 * time.Second)
+	for !doc.BufEOF() {
+		if time.Now().After(deadline) {
+			t.Fatal("Document.SortDocument() does not sort the pipe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []string{"a,3", "b,1", "c,2"}
+	if got := docLines(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.SortDocument() = %v, want %v", got, want)
+	}
+}
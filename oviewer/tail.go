@@ -0,0 +1,196 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// lazyThreshold is the number of unread bytes above which
+// moving to the bottom reads the tail of the file
+// instead of waiting for the line index to reach the end.
+var lazyThreshold int64 = 32 << 20
+
+// tailLines is the number of lines to read from the end of the file.
+const tailLines = 1000
+
+// tailBlock is the size of the block to read backward from the end.
+const tailBlock = 64 << 10
+
+// lineTail represents the last lines of the file.
+// lineTail is displayed until the line index reaches the end of the file.
+type lineTail struct {
+	// start is the offset of the first line of the tail.
+	start int64
+	// end is the offset following the last line of the tail.
+	end int64
+	// lines stores the last lines.
+	lines []string
+}
+
+// readTail reads the last num lines before size from the file.
+func readTail(f *os.File, size int64, num int) (*lineTail, error) {
+	pos := size
+	var buf []byte
+	for pos > 0 {
+		n := min64(tailBlock, pos)
+		pos -= n
+		block := make([]byte, n)
+		if _, err := f.ReadAt(block, pos); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read tail: %w", err)
+		}
+		buf = append(block, buf...)
+		// One more line terminator is needed to find the beginning of the line.
+		if bytes.Count(buf, []byte("\n")) > num {
+			break
+		}
+	}
+
+	start := pos
+	if pos > 0 {
+		// Skip the line that started before pos.
+		i := bytes.IndexByte(buf, '\n')
+		buf = buf[i+1:]
+		start += int64(i + 1)
+	}
+
+	var lines []string
+	var lens []int
+	for len(buf) > 0 {
+		l := len(buf)
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			l = i + 1
+		}
+		lines = append(lines, trimLineEnd(string(buf[:l])))
+		lens = append(lens, l)
+		buf = buf[l:]
+	}
+	for len(lines) > num {
+		start += int64(lens[0])
+		lines = lines[1:]
+		lens = lens[1:]
+	}
+
+	return &lineTail{
+		start: start,
+		end:   size,
+		lines: lines,
+	}, nil
+}
+
+// openTail reads the end of the file when the line index is far from the end.
+// While reading the tail, the lines of the tail are displayed after the header (see viewLine),
+// so that the end of the file can be displayed immediately.
+// The lines of the document returned by GetLine and BufEndNum are not changed.
+func (m *Document) openTail() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The end of the compressed file cannot be read without decompressing it.
+	if m.tail != nil || m.index == nil || m.index.seek != nil || m.BufEOF() {
+		return
+	}
+	fi, err := m.index.file.Stat()
+	if err != nil {
+		log.Printf("openTail: %s", err)
+		return
+	}
+	if fi.Size()-m.index.end < lazyThreshold {
+		return
+	}
+
+	tail, err := readTail(m.index.file, fi.Size(), tailLines)
+	if err != nil {
+		log.Printf("openTail: %s", err)
+		return
+	}
+	m.tail = tail
+	m.ClearCache()
+	atomic.StoreInt32(&m.changed, 1)
+}
+
+// closeTail switches from the tail to the line index
+// after the line index covers the tail.
+// The eof flag is not used because it is cleared in follow mode.
+// The lines displayed on the screen and the marks do not move.
+func (m *Document) closeTail() {
+	m.mu.Lock()
+	if m.tail == nil {
+		m.mu.Unlock()
+		return
+	}
+	lN := 0
+	if m.index != nil {
+		if m.index.end < m.tail.end && !m.BufEOF() {
+			m.mu.Unlock()
+			return
+		}
+		var ok bool
+		lN, ok = m.index.lineAt(m.tail.start)
+		if !ok {
+			// The file has been changed.
+			lN = max(0, m.endNum-len(m.tail.lines))
+		}
+	}
+	m.tail = nil
+	m.mu.Unlock()
+
+	// The tail is displayed after the header.
+	shift := lN - m.firstLine()
+	m.topLN = max(m.topLN+shift, 0)
+	for i := range m.marked {
+		m.marked[i] += shift
+	}
+	m.ClearCache()
+	m.lastContentsNum = -1
+}
+
+// tailStatus returns the estimated line number of the beginning of the tail
+// and the estimated total number of lines while reading the tail.
+func (m *Document) tailStatus() (int, int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tail == nil || m.index == nil || m.index.end == 0 {
+		return 0, 0, false
+	}
+	start := m.tailStart()
+	return start, start + len(m.tail.lines), true
+}
+
+// tailStart returns the line number of the beginning of the tail,
+// which is estimated from the lines read if the line index has not reached it.
+// m.mu must be locked.
+func (m *Document) tailStart() int {
+	if m.index == nil || m.index.end == 0 {
+		return 0
+	}
+	if lN, ok := m.index.lineAt(m.tail.start); ok {
+		return lN
+	}
+	perByte := float64(m.endNum) / float64(m.index.end)
+	return int(float64(m.tail.start) * perByte)
+}
+
+// dropTail stops displaying the tail before the line index reaches it,
+// so that the line numbers of the screen are those of the document.
+// The marks are moved to the estimated line numbers.
+func (m *Document) dropTail() {
+	m.mu.Lock()
+	if m.tail == nil {
+		m.mu.Unlock()
+		return
+	}
+	shift := m.tailStart() - m.firstLine()
+	m.tail = nil
+	m.mu.Unlock()
+
+	for i := range m.marked {
+		m.marked[i] += shift
+	}
+	m.ClearCache()
+	m.lastContentsNum = -1
+}
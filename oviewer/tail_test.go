@@ -0,0 +1,273 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_readTail(t *testing.T) {
+	type args struct {
+		str string
+		num int
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantStart int64
+		wantLines []string
+	}{
+		{
+			name: "testShort",
+			args: args{
+				str: "a\nb\nc\n",
+				num: 10,
+			},
+			wantStart: 0,
+			wantLines: []string{"a", "b", "c"},
+		},
+		{
+			name: "testLast",
+			args: args{
+				str: "a\nb\nc\n",
+				num: 2,
+			},
+			wantStart: 2,
+			wantLines: []string{"b", "c"},
+		},
+		{
+			name: "testNoEOL",
+			args: args{
+				str: "a\nb\nc",
+				num: 2,
+			},
+			wantStart: 2,
+			wantLines: []string{"b", "c"},
+		},
+		{
+			name: "testBlocks",
+			args: args{
+				str: strings.Repeat(strings.Repeat("x", 99)+"\n", 2000),
+				num: 1000,
+			},
+			wantStart: 100000,
+			wantLines: strings.Split(strings.Repeat(strings.Repeat("x", 99)+"\n", 1000), "\n")[:1000],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "tail.txt")
+			if err := os.WriteFile(fileName, []byte(tt.args.str), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := readTail(f, int64(len(tt.args.str)), tt.args.num)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.start != tt.wantStart {
+				t.Errorf("readTail() start = %v, want %v", got.start, tt.wantStart)
+			}
+			if !reflect.DeepEqual(got.lines, tt.wantLines) {
+				t.Errorf("readTail() lines = %v, want %v", got.lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestDocument_closeTail(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "tail.txt")
+	var b strings.Builder
+	for i := 0; i < chunkLines*2; i++ {
+		b.WriteString(strings.Repeat("y", i%10) + "\n")
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tail, err := readTail(m.index.file, int64(b.Len()), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.tail = tail
+	m.mu.Unlock()
+	if got := m.viewEndNum(); got != 100 {
+		t.Errorf("Document.viewEndNum() = %v, want %v", got, 100)
+	}
+	if got := m.BufEndNum(); got != chunkLines*2 {
+		t.Errorf("Document.BufEndNum() = %v, want %v", got, chunkLines*2)
+	}
+	m.topLN = 10
+	want := m.viewLine(m.topLN)
+
+	m.closeTail()
+	if m.topLN != chunkLines*2-100+10 {
+		t.Errorf("Document.closeTail() topLN = %v, want %v", m.topLN, chunkLines*2-100+10)
+	}
+	if got := m.viewLine(m.topLN); got != want {
+		t.Errorf("Document.viewLine() = %v, want %v", got, want)
+	}
+	if got := m.BufEndNum(); got != chunkLines*2 {
+		t.Errorf("Document.BufEndNum() = %v, want %v", got, chunkLines*2)
+	}
+}
+
+func TestDocument_closeTailFollow(t *testing.T) {
+	threshold := lazyThreshold
+	lazyThreshold = 0
+	defer func() {
+		lazyThreshold = threshold
+	}()
+	fileName := filepath.Join(t.TempDir(), "follow.log")
+	if err := os.WriteFile(fileName, []byte(strings.Repeat("line\n", 100)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.onceFollowMode()
+	defer m.cancel()
+	// The file closed at EOF is reopened when the watcher notifies the change.
+	m.changCh <- struct{}{}
+	// The eof flag is cleared in follow mode.
+	for m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.openTail()
+	if got := m.viewEndNum(); got != 100 {
+		t.Fatalf("Document.viewEndNum() = %v, want %v", got, 100)
+	}
+
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("NEWLINE\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		m.closeTail()
+		if m.viewLine(m.viewEndNum()-1) == "NEWLINE" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Document.viewLine() = %v, want %v", m.viewLine(m.viewEndNum()-1), "NEWLINE")
+}
+
+func TestDocument_closeTailLoadIndex(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "tail.txt")
+	if err := os.WriteFile(fileName, []byte(strings.Repeat("line\n", 200)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	tail, err := readTail(m.index.file, 200*5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.tail = tail
+	m.mu.Unlock()
+
+	// The line index is closed by reload in watch mode.
+	if err := m.loadIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := m.tailStatus(); ok {
+		t.Errorf("Document.tailStatus() ok = true, want false")
+	}
+	m.closeTail()
+	if got := m.BufEndNum(); got != 200 {
+		t.Errorf("Document.BufEndNum() = %v, want %v", got, 200)
+	}
+}
+
+func TestDocument_viewLineTail(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "tail.txt")
+	var b strings.Builder
+	b.WriteString("header\n")
+	for i := 1; i < chunkLines*2; i++ {
+		fmt.Fprintf(&b, "line%d\n", i)
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.closeIndex()
+	for !m.BufEOF() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	tail, err := readTail(m.index.file, int64(b.Len()), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.tail = tail
+	m.mu.Unlock()
+	m.Header = 1
+
+	// The tail is displayed after the header.
+	if got := m.viewLine(0); got != "header" {
+		t.Errorf("Document.viewLine(0) = %v, want %v", got, "header")
+	}
+	if got := m.viewLine(1); got != tail.lines[0] {
+		t.Errorf("Document.viewLine(1) = %v, want %v", got, tail.lines[0])
+	}
+	if got := m.viewEndNum(); got != 101 {
+		t.Errorf("Document.viewEndNum() = %v, want %v", got, 101)
+	}
+	// The lines of the document are not changed.
+	if got := m.GetLine(1); got != "line1" {
+		t.Errorf("Document.GetLine(1) = %v, want %v", got, "line1")
+	}
+	// The lines of the tail are searched.
+	last := fmt.Sprintf("line%d", chunkLines*2-1)
+	if got, err := m.SearchLine(context.Background(), NewSearcher(last, nil, false, false), 0); err != nil || got != 100 {
+		t.Errorf("Document.SearchLine() = %v, %v, want %v", got, err, 100)
+	}
+
+	// Moving to the top displays the lines of the document.
+	m.dropTail()
+	if got := m.viewEndNum(); got != chunkLines*2 {
+		t.Errorf("Document.viewEndNum() = %v, want %v", got, chunkLines*2)
+	}
+	if got := m.viewLine(1); got != "line1" {
+		t.Errorf("Document.viewLine(1) = %v, want %v", got, "line1")
+	}
+}
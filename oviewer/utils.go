@@ -16,6 +16,22 @@ func min(a, b int) int {
 	return b
 }
 
+// min64 returns the smaller value of the argument.
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// max64 returns the larger value of the argument.
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // removeStr removes the value of the specified string from slice.
 func removeStr(list []string, s string) []string {
 	if len(s) == 0 {
@@ -30,6 +46,16 @@ func removeStr(list []string, s string) []string {
 	return list
 }
 
+// containsStr returns true if the slice contains the string.
+func containsStr(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
 // removeStr removes the specified int value from slice.
 func removeInt(list []int, c int) []int {
 	for n, l := range list {